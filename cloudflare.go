@@ -9,6 +9,7 @@ import (
 	"io"
 	"log"
 	"math"
+	"math/rand"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
@@ -47,12 +48,20 @@ type API struct {
 	APIToken          string
 	BaseURL           string
 	UserAgent         string
+	userAgentPrefix   string
 	headers           http.Header
 	httpClient        *http.Client
 	authType          int
 	rateLimiter       *rate.Limiter
 	retryPolicy       RetryPolicy
 	logger            Logger
+	requestHook       RequestHook
+	tracer            Tracer
+	strictDecoding    bool
+	defaultAccountID  string
+	accountRouteRoot  RouteRoot
+	debugLogger       Logger
+	tunnelRouteCaches *tunnelRouteCacheSet
 	Debug             bool
 }
 
@@ -70,7 +79,8 @@ func newClient(opts ...Option) (*API, error) {
 			MinRetryDelay: 1 * time.Second,
 			MaxRetryDelay: 30 * time.Second,
 		},
-		logger: silentLogger,
+		logger:            silentLogger,
+		tunnelRouteCaches: &tunnelRouteCacheSet{},
 	}
 
 	err := api.parseOptions(opts...)
@@ -146,8 +156,14 @@ func (api *API) SetAuthType(authType int) {
 
 // ZoneIDByName retrieves a zone's ID from the name.
 func (api *API) ZoneIDByName(zoneName string) (string, error) {
+	return api.ZoneIDByNameContext(context.Background(), zoneName)
+}
+
+// ZoneIDByNameContext retrieves a zone's ID from the name, using ctx for the
+// underlying request.
+func (api *API) ZoneIDByNameContext(ctx context.Context, zoneName string) (string, error) {
 	zoneName = normalizeZoneName(zoneName)
-	res, err := api.ListZonesContext(context.Background(), WithZoneFilters(zoneName, "", ""))
+	res, err := api.ListZonesContext(ctx, WithZoneFilters(zoneName, "", ""))
 	if err != nil {
 		return "", fmt.Errorf("ListZonesContext command failed: %w", err)
 	}
@@ -192,6 +208,12 @@ type APIResponse struct {
 	Headers    http.Header
 }
 
+// RayID returns the CF-Ray identifier of the response, which Cloudflare
+// support can use to locate the request.
+func (r *APIResponse) RayID() string {
+	return r.Headers.Get("cf-ray")
+}
+
 func (api *API) makeRequestWithAuthTypeAndHeaders(ctx context.Context, method, uri string, params interface{}, authType int, headers http.Header) ([]byte, error) {
 	res, err := api.makeRequestWithAuthTypeAndHeadersComplete(ctx, method, uri, params, authType, headers)
 	if err != nil {
@@ -210,8 +232,11 @@ func (api *API) makeRequestWithAuthTypeAndHeadersComplete(ctx context.Context, m
 	var resp *http.Response
 	var respErr error
 	var respBody []byte
+	var retryAfter time.Duration
 
-	for i := 0; i <= api.retryPolicy.MaxRetries; i++ {
+	retryPolicy := api.retryPolicyFromContext(ctx)
+
+	for i := 0; i <= retryPolicy.MaxRetries; i++ {
 		var reqBody io.Reader
 		if params != nil {
 			if r, ok := params.(io.Reader); ok {
@@ -230,20 +255,36 @@ func (api *API) makeRequestWithAuthTypeAndHeadersComplete(ctx context.Context, m
 
 		if i > 0 {
 			// expect the backoff introduced here on errored requests to dominate the effect of rate limiting
-			// don't need a random component here as the rate limiter should do something similar
 			// nb time duration could truncate an arbitrary float. Since our inputs are all ints, we should be ok
-			sleepDuration := time.Duration(math.Pow(2, float64(i-1)) * float64(api.retryPolicy.MinRetryDelay))
+			sleepDuration := time.Duration(math.Pow(2, float64(i-1)) * float64(retryPolicy.MinRetryDelay))
+
+			// add up to 20% jitter so that concurrent callers backing off
+			// from the same failure don't all retry in lockstep
+			if jitter := int64(sleepDuration / 5); jitter > 0 {
+				sleepDuration += time.Duration(rand.Int63n(jitter)) //nolint:gosec
+			}
+
+			if sleepDuration > retryPolicy.MaxRetryDelay {
+				sleepDuration = retryPolicy.MaxRetryDelay
+			}
 
-			if sleepDuration > api.retryPolicy.MaxRetryDelay {
-				sleepDuration = api.retryPolicy.MaxRetryDelay
+			// the server knows best how long we need to wait so honour any
+			// Retry-After it has sent us, up to MaxRetryDelay so that a bogus
+			// value cannot stall the call indefinitely
+			if retryAfter > sleepDuration {
+				sleepDuration = retryAfter
+				if sleepDuration > retryPolicy.MaxRetryDelay {
+					sleepDuration = retryPolicy.MaxRetryDelay
+				}
 			}
+
 			// useful to do some simple logging here, maybe introduce levels later
 			api.logger.Printf("Sleeping %s before retry attempt number %d for request %s %s", sleepDuration.String(), i, method, uri)
 
 			select {
 			case <-time.After(sleepDuration):
 			case <-ctx.Done():
-				return nil, fmt.Errorf("operation aborted during backoff: %w", ctx.Err())
+				return nil, fmt.Errorf("operation aborted during backoff (last error: %v): %w", respErr, ctx.Err())
 			}
 		}
 
@@ -261,7 +302,25 @@ func (api *API) makeRequestWithAuthTypeAndHeadersComplete(ctx context.Context, m
 
 		// retry if the server is rate limiting us or if it failed
 		// assumes server operations are rolled back on failure
-		if respErr != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		if respErr != nil || retryPolicy.retryable(resp.StatusCode) {
+			retryAfter = 0
+			if resp != nil {
+				retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+			}
+
+			// fail fast with the rate limit error rather than waiting past
+			// the deadline only for the context to be cancelled
+			if resp != nil && resp.StatusCode == http.StatusTooManyRequests && retryAfterExceedsDeadline(ctx, retryAfter) {
+				respBody, err = io.ReadAll(resp.Body)
+				defer resp.Body.Close()
+				if err != nil {
+					return nil, fmt.Errorf("could not read response body: %w", err)
+				}
+
+				respErr = nil
+				break
+			}
+
 			if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
 				respErr = errors.New("exceeded available rate limit retries")
 			}
@@ -350,6 +409,92 @@ func (api *API) makeRequestWithAuthTypeAndHeadersComplete(ctx context.Context, m
 	}, nil
 }
 
+// retryAfterExceedsDeadline reports whether waiting for retryAfter would take
+// longer than the time remaining before the deadline of ctx, if it has one.
+func retryAfterExceedsDeadline(ctx context.Context, retryAfter time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	if !ok || retryAfter == 0 {
+		return false
+	}
+
+	return retryAfter > time.Until(deadline)
+}
+
+// parseRetryAfter parses the value of a Retry-After header which may either be
+// a number of seconds or a HTTP date. A zero duration is returned if the value
+// is missing or invalid.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if d := time.Until(date); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
+
+type retryPolicyContextKey struct{}
+
+// WithRetryPolicy returns a copy of ctx which overrides the retry policy of
+// the client for any requests made using it. This allows the number of
+// retries and the backoff delays to be tuned per call.
+func WithRetryPolicy(ctx context.Context, policy RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyContextKey{}, policy)
+}
+
+// retryPolicyFromContext returns the retry policy set on ctx using
+// WithRetryPolicy, falling back to the retry policy of the client.
+func (api *API) retryPolicyFromContext(ctx context.Context) RetryPolicy {
+	if policy, ok := ctx.Value(retryPolicyContextKey{}).(RetryPolicy); ok {
+		return policy
+	}
+
+	return api.retryPolicy
+}
+
+type requestIDContextKey struct{}
+
+// WithRequestID returns a copy of ctx which sends id as the X-Request-ID header
+// of any requests made using it, allowing calls to be correlated with other
+// systems.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, id)
+}
+
+type extraHeadersContextKey struct{}
+
+// WithExtraHeaders returns a copy of ctx which adds headers to any requests
+// made using it, such as the opt-in headers needed by beta features. They take
+// precedence over headers set using the Headers option, but not over those a
+// method sets itself such as If-Match. The credential headers
+// Authorization, X-Auth-Key, X-Auth-Email and X-Auth-User-Service-Key cannot
+// be overridden and are ignored.
+func WithExtraHeaders(ctx context.Context, headers http.Header) context.Context {
+	return context.WithValue(ctx, extraHeadersContextKey{}, headers)
+}
+
+// isCredentialHeader reports whether the header carries the credentials of the
+// client.
+func isCredentialHeader(key string) bool {
+	switch http.CanonicalHeaderKey(key) {
+	case "Authorization", "X-Auth-Key", "X-Auth-Email", "X-Auth-User-Service-Key":
+		return true
+	}
+
+	return false
+}
+
 // request makes a HTTP request to the given API endpoint, returning the raw
 // *http.Response, or an error if one occurred. The caller is responsible for
 // closing the response body.
@@ -361,6 +506,13 @@ func (api *API) request(ctx context.Context, method, uri string, reqBody io.Read
 
 	combinedHeaders := make(http.Header)
 	copyHeader(combinedHeaders, api.headers)
+	if extra, ok := ctx.Value(extraHeadersContextKey{}).(http.Header); ok {
+		for k, vs := range extra {
+			if !isCredentialHeader(k) {
+				combinedHeaders[http.CanonicalHeaderKey(k)] = vs
+			}
+		}
+	}
 	copyHeader(combinedHeaders, headers)
 	req.Header = combinedHeaders
 
@@ -375,8 +527,20 @@ func (api *API) request(ctx context.Context, method, uri string, reqBody io.Read
 		req.Header.Set("Authorization", "Bearer "+api.APIToken)
 	}
 
-	if api.UserAgent != "" {
-		req.Header.Set("User-Agent", api.UserAgent)
+	userAgent := api.UserAgent
+	if api.userAgentPrefix != "" {
+		userAgent = strings.TrimSpace(api.userAgentPrefix + " " + userAgent)
+	}
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+
+	if id, ok := ctx.Value(requestIDContextKey{}).(string); ok && id != "" {
+		req.Header.Set("X-Request-ID", id)
+	}
+
+	if api.tracer != nil {
+		api.tracer.Inject(ctx, req.Header)
 	}
 
 	if req.Header.Get("Content-Type") == "" {
@@ -388,19 +552,28 @@ func (api *API) request(ctx context.Context, method, uri string, reqBody io.Read
 		if err != nil {
 			return nil, err
 		}
+		api.debugLog(api.redactDebugDump(dump))
+	}
 
-		// Strip out any sensitive information from the request payload.
-		sensitiveKeys := []string{api.APIKey, api.APIEmail, api.APIToken, api.APIUserServiceKey}
-		for _, key := range sensitiveKeys {
-			if key != "" {
-				valueRegex := regexp.MustCompile(fmt.Sprintf("(?m)%s", key))
-				dump = valueRegex.ReplaceAll(dump, []byte("[redacted]"))
-			}
-		}
-		log.Printf("\n%s", string(dump))
+	if api.requestHook != nil {
+		api.requestHook.OnRequestStart(ctx, method, uri)
 	}
 
+	start := time.Now()
 	resp, err := api.httpClient.Do(req)
+
+	if api.requestHook != nil {
+		info := RequestHookInfo{Method: method, Path: uri, Duration: time.Since(start), Err: err}
+		if resp != nil {
+			info.StatusCode = resp.StatusCode
+		}
+		api.requestHook.OnRequestEnd(ctx, info)
+	}
+
+	if span, ok := ctx.Value(spanContextKey{}).(Span); ok && resp != nil {
+		span.SetAttribute(SpanAttributeHTTPStatusCode, resp.StatusCode)
+	}
+
 	if err != nil {
 		return nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
@@ -410,12 +583,62 @@ func (api *API) request(ctx context.Context, method, uri string, reqBody io.Read
 		if err != nil {
 			return resp, err
 		}
-		log.Printf("\n%s", string(dump))
+
+		// the token endpoints return nothing but the token as the result
+		if strings.HasSuffix(req.URL.Path, "/token") {
+			dump = debugTokenResultRegex.ReplaceAll(dump, []byte(`$1"[redacted]"`))
+		}
+		api.debugLog(api.redactDebugDump(dump))
 	}
 
 	return resp, nil
 }
 
+var (
+	debugAuthHeaderRegex  = regexp.MustCompile(`(?mi)^(Authorization|X-Auth-Key|X-Auth-Email|X-Auth-User-Service-Key):[^\r\n]*`)
+	debugSecretFieldRegex = regexp.MustCompile(`("(?:tunnel_secret|secret|token)"\s*:\s*)"[^"]*"`)
+	debugTokenResultRegex = regexp.MustCompile(`("result"\s*:\s*)"[^"]*"`)
+)
+
+// redactDebugDump strips credentials and secrets, such as tunnel secrets and
+// tokens, from a dumped request or response before it is logged.
+func (api *API) redactDebugDump(dump []byte) []byte {
+	dump = debugAuthHeaderRegex.ReplaceAll(dump, []byte("$1: [redacted]"))
+	dump = debugSecretFieldRegex.ReplaceAll(dump, []byte(`$1"[redacted]"`))
+
+	for _, key := range []string{api.APIKey, api.APIEmail, api.APIToken, api.APIUserServiceKey} {
+		if key != "" {
+			dump = bytes.ReplaceAll(dump, []byte(key), []byte("[redacted]"))
+		}
+	}
+
+	return dump
+}
+
+// debugLog writes a dumped request or response to the debug logger, falling
+// back to the standard logger when none has been set.
+func (api *API) debugLog(dump []byte) {
+	if api.debugLogger != nil {
+		api.debugLogger.Printf("\n%s", string(dump))
+		return
+	}
+
+	log.Printf("\n%s", string(dump))
+}
+
+// unmarshalResponse decodes a response body into v. When strict decoding is
+// enabled fields which v does not have are an error, rather than ignored, so
+// that changes to the API responses are noticed.
+func (api *API) unmarshalResponse(data []byte, v interface{}) error {
+	if !api.strictDecoding {
+		return json.Unmarshal(data, v)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
 // copyHeader copies all headers for `source` and sets them on `target`.
 // based on https://godoc.org/github.com/golang/gddo/httputil/header#Copy
 func copyHeader(target, source http.Header) {
@@ -486,12 +709,121 @@ type PaginationOptions struct {
 
 // RetryPolicy specifies number of retries and min/max retry delays
 // This config is used when the client exponentially backs off after errored requests.
+// By default requests are retried up to 3 times, waiting from 1 up to 30 seconds.
+// A Retry-After sent by the server is honoured, but never waited on for longer
+// than MaxRetryDelay.
 type RetryPolicy struct {
 	MaxRetries    int
 	MinRetryDelay time.Duration
 	MaxRetryDelay time.Duration
+	// RetryableStatusCodes lists the response status codes which are retried.
+	// When empty, 429 and all 5xx responses are retried. Failures to make the
+	// request at all are always retried.
+	RetryableStatusCodes []int
+}
+
+// retryable reports whether a response with the status code should be retried.
+func (p RetryPolicy) retryable(statusCode int) bool {
+	if len(p.RetryableStatusCodes) == 0 {
+		return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+	}
+
+	for _, code := range p.RetryableStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RequestHook is notified of every HTTP request made to the API, including
+// retries, allowing metrics such as latency and status codes to be recorded.
+type RequestHook interface {
+	// OnRequestStart is called immediately before a request is sent.
+	OnRequestStart(ctx context.Context, method, path string)
+	// OnRequestEnd is called once the response headers have been received or
+	// the request has failed.
+	OnRequestEnd(ctx context.Context, info RequestHookInfo)
+}
+
+// RequestHookInfo describes a completed request for RequestHook.OnRequestEnd.
+type RequestHookInfo struct {
+	Method string
+	// Path is relative to the base URL of the client and includes any query
+	// string.
+	Path string
+	// StatusCode is zero when no response was received.
+	StatusCode int
+	Duration   time.Duration
+	Err        error
+}
+
+// Span attributes set by the API on spans started by a Tracer.
+const (
+	SpanAttributeAccountID      = "cloudflare.account_id"
+	SpanAttributeTunnelID       = "cloudflare.tunnel_id"
+	SpanAttributeHTTPStatusCode = "http.status_code"
+)
+
+// Tracer starts spans around API calls so that they appear in distributed
+// traces. It is intended to be a thin adapter over a tracing library such as
+// OpenTelemetry, which this library does not depend on.
+//
+// Spans are named after the method, such as "cloudflare.ListTunnelRoutes",
+// and have the SpanAttribute attributes set where known. When a call makes
+// several requests, the status code is that of the last response.
+type Tracer interface {
+	// Start starts a span as a child of any span in ctx, returning a copy of
+	// ctx containing the new span.
+	Start(ctx context.Context, name string) (context.Context, Span)
+	// Inject adds the trace context of ctx to the headers of a request, for
+	// example using the W3C traceparent header.
+	Inject(ctx context.Context, header http.Header)
+}
+
+// Span is a single operation started by a Tracer.
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
 }
 
+type spanContextKey struct{}
+
+// startSpan starts a span for the operation when a tracer has been set,
+// otherwise a span which does nothing is returned. The span should be ended
+// using endSpan.
+func (api *API) startSpan(ctx context.Context, operation string, rc *ResourceContainer, tunnelID string) (context.Context, Span) {
+	if api.tracer == nil {
+		return ctx, noopSpan{}
+	}
+
+	ctx, span := api.tracer.Start(ctx, "cloudflare."+operation)
+	if rc != nil && rc.Identifier != "" {
+		span.SetAttribute(SpanAttributeAccountID, rc.Identifier)
+	}
+	if tunnelID != "" {
+		span.SetAttribute(SpanAttributeTunnelID, tunnelID)
+	}
+
+	return context.WithValue(ctx, spanContextKey{}, span), span
+}
+
+// endSpan records err, if any, on the span and ends it.
+func endSpan(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, interface{}) {}
+func (noopSpan) RecordError(error)                {}
+func (noopSpan) End()                             {}
+
 // Logger defines the interface this library needs to use logging
 // This is a subset of the methods implemented in the log package.
 type Logger interface {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -46,6 +47,60 @@ func teardown() {
 	server.Close()
 }
 
+func TestClient_UserAgentPrefix(t *testing.T) {
+	setup(UserAgentPrefix("my-software/1.2.3"))
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "my-software/1.2.3 cloudflare-go/"+Version, r.Header.Get("User-Agent"))
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": []}`)
+	})
+
+	_, _, err := client.ListTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesListParams{})
+	assert.NoError(t, err)
+}
+
+func TestClient_UserAgentPrefix_Options(t *testing.T) {
+	testCases := map[string]struct {
+		opts []Option
+		want string
+	}{
+		"prefix before user agent": {
+			opts: []Option{UserAgentPrefix("my-software/1.2.3"), UserAgent("custom/1.0")},
+			want: "my-software/1.2.3 custom/1.0",
+		},
+		"prefix after user agent": {
+			opts: []Option{UserAgent("custom/1.0"), UserAgentPrefix("my-software/1.2.3")},
+			want: "my-software/1.2.3 custom/1.0",
+		},
+		"empty prefix": {
+			opts: []Option{UserAgentPrefix("")},
+			want: "cloudflare-go/" + Version,
+		},
+		"empty user agent": {
+			opts: []Option{UserAgent(""), UserAgentPrefix("my-software/1.2.3")},
+			want: "my-software/1.2.3",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			setup(tc.opts...)
+			defer teardown()
+
+			mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, tc.want, r.Header.Get("User-Agent"))
+				w.Header().Set("content-type", "application/json")
+				fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": []}`)
+			})
+
+			_, _, err := client.ListTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesListParams{})
+			assert.NoError(t, err)
+		})
+	}
+}
+
 func TestClient_Headers(t *testing.T) {
 	// it should set default headers
 	setup()
@@ -219,6 +274,144 @@ func TestClient_RetryReturnsPersistentErrorResponse(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestClient_RetryPolicyFromContext(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requestsReceived := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestsReceived++
+		w.Header().Set("content-type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, `{"success": false, "errors": [], "messages": [], "result": null}`)
+	}
+
+	mux.HandleFunc("/user/load_balancers/pools", handler)
+
+	ctx := WithRetryPolicy(context.Background(), RetryPolicy{MaxRetries: 2})
+	_, err := client.ListLoadBalancerPools(ctx, UserIdentifier(testUserID), ListLoadBalancerPoolParams{})
+	assert.Error(t, err)
+	assert.Equal(t, 3, requestsReceived)
+}
+
+func TestClient_RetryableStatusCodes(t *testing.T) {
+	setup(UsingRetryPolicy(2, 0, 0), UsingRetryableStatusCodes(http.StatusConflict))
+	defer teardown()
+
+	var status int
+	requestsReceived := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestsReceived++
+		w.Header().Set("content-type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprint(w, `{"success": false, "errors": [], "messages": [], "result": null}`)
+	}
+
+	mux.HandleFunc("/user/load_balancers/pools", handler)
+
+	status = http.StatusConflict
+	_, err := client.ListLoadBalancerPools(context.Background(), UserIdentifier(testUserID), ListLoadBalancerPoolParams{})
+	assert.Error(t, err)
+	assert.Equal(t, 3, requestsReceived)
+
+	requestsReceived = 0
+	status = http.StatusServiceUnavailable
+	_, err = client.ListLoadBalancerPools(context.Background(), UserIdentifier(testUserID), ListLoadBalancerPoolParams{})
+	var serviceErr *ServiceError
+	assert.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, 1, requestsReceived)
+}
+
+func TestClient_RetryHonoursRetryAfter(t *testing.T) {
+	setup(UsingRetryPolicy(1, 0, 2))
+	defer teardown()
+
+	var firstRequestAt time.Time
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		if firstRequestAt.IsZero() {
+			firstRequestAt = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"success": false, "errors": [], "messages": [], "result": null}`)
+			return
+		}
+
+		assert.GreaterOrEqual(t, time.Since(firstRequestAt), time.Second)
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": []}`)
+	}
+
+	mux.HandleFunc("/user/load_balancers/pools", handler)
+
+	_, err := client.ListLoadBalancerPools(context.Background(), UserIdentifier(testUserID), ListLoadBalancerPoolParams{})
+	assert.NoError(t, err)
+}
+
+func TestClient_RetryAfterCappedAtMaxRetryDelay(t *testing.T) {
+	setup(UsingRetryPolicy(1, 0, 1))
+	defer teardown()
+
+	var firstRequestAt time.Time
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		if firstRequestAt.IsZero() {
+			firstRequestAt = time.Now()
+			w.Header().Set("Retry-After", "86400")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"success": false, "errors": [], "messages": [], "result": null}`)
+			return
+		}
+
+		assert.GreaterOrEqual(t, time.Since(firstRequestAt), time.Second)
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": []}`)
+	}
+
+	mux.HandleFunc("/user/load_balancers/pools", handler)
+
+	start := time.Now()
+	_, err := client.ListLoadBalancerPools(context.Background(), UserIdentifier(testUserID), ListLoadBalancerPoolParams{})
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestClient_RetryAfterBeyondDeadlineFailsFast(t *testing.T) {
+	setup(UsingRetryPolicy(3, 0, 0))
+	defer teardown()
+
+	requestsReceived := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestsReceived++
+		w.Header().Set("content-type", "application/json")
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"success": false, "errors": [{"code": 10000, "message": "rate limited"}], "messages": [], "result": null}`)
+	}
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", handler)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, _, err := client.ListTunnelRoutes(ctx, AccountIdentifier(testAccountID), TunnelRoutesListParams{})
+
+	var rateLimitErr *RatelimitError
+	assert.ErrorAs(t, err, &rateLimitErr)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, 1, requestsReceived)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	assert.Equal(t, time.Duration(0), parseRetryAfter(""))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon"))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("-5"))
+	assert.Equal(t, 30*time.Second, parseRetryAfter("30"))
+	assert.Equal(t, time.Duration(0), parseRetryAfter(time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)))
+
+	d := parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	assert.True(t, d > 59*time.Minute && d <= time.Hour, "unexpected duration %s", d)
+}
+
 func TestZoneIDByNameWithNonUniqueZonesWithoutOrgID(t *testing.T) {
 	setup()
 	defer teardown()
@@ -449,6 +642,33 @@ func TestZoneIDByNameWithIDN(t *testing.T) {
 	}
 }
 
+func TestZoneIDByNameContext(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/zones", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "example.com", r.URL.Query().Get("name"))
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": [{"id": "023e105f4ecef8ad9ca31a8372d0c353", "name": "example.com"}],
+			"result_info": {"page": 1, "per_page": 50, "count": 1, "total_count": 1, "total_pages": 1}
+		}`)
+	})
+
+	actual, err := client.ZoneIDByNameContext(context.Background(), "example.com")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "023e105f4ecef8ad9ca31a8372d0c353", actual)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.ZoneIDByNameContext(ctx, "example.com")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
 func TestClient_ContextIsPassedToRequest(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
@@ -547,3 +767,83 @@ func TestCheckResultInfo(t *testing.T) {
 		})
 	}
 }
+
+type recordingRequestHook struct {
+	started []string
+	ended   []RequestHookInfo
+}
+
+func (h *recordingRequestHook) OnRequestStart(ctx context.Context, method, path string) {
+	h.started = append(h.started, method+" "+path)
+}
+
+func (h *recordingRequestHook) OnRequestEnd(ctx context.Context, info RequestHookInfo) {
+	h.ended = append(h.ended, info)
+}
+
+func TestClient_RequestHook(t *testing.T) {
+	hook := &recordingRequestHook{}
+	setup(UsingRequestHook(hook), UsingRetryPolicy(1, 0, 0))
+	defer teardown()
+
+	requestsReceived := 0
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		requestsReceived++
+		w.Header().Set("content-type", "application/json")
+		if requestsReceived == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"success": false, "errors": [], "messages": [], "result": null}`)
+			return
+		}
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": []}`)
+	})
+
+	_, _, err := client.ListTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesListParams{})
+	assert.NoError(t, err)
+
+	path := "/accounts/" + testAccountID + "/teamnet/routes?per_page=100"
+	assert.Equal(t, []string{"GET " + path, "GET " + path}, hook.started)
+	if assert.Len(t, hook.ended, 2) {
+		assert.Equal(t, http.StatusServiceUnavailable, hook.ended[0].StatusCode)
+		assert.Equal(t, http.StatusOK, hook.ended[1].StatusCode)
+		assert.Equal(t, path, hook.ended[1].Path)
+		assert.NoError(t, hook.ended[1].Err)
+	}
+}
+
+type recordingLogger struct {
+	lines []string
+}
+
+func (l *recordingLogger) Printf(format string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func TestClient_DebugLoggerRedactsSecrets(t *testing.T) {
+	logger := &recordingLogger{}
+	setup(UsingDebugLogger(logger))
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/cfd_tunnel", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": {"id": "%s", "name": "blog", "tunnel_secret": "c2VjcmV0LXNlY3JldA=="}}`, testTunnelID)
+	})
+	mux.HandleFunc("/accounts/"+testAccountID+"/cfd_tunnel/"+testTunnelID+"/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": "ZXhhbXBsZS10b2tlbg=="}`)
+	})
+
+	_, err := client.CreateTunnel(context.Background(), AccountIdentifier(testAccountID), TunnelCreateParams{Name: "blog", Secret: "c2VjcmV0LXNlY3JldA=="})
+	assert.NoError(t, err)
+	_, err = client.GetTunnelToken(context.Background(), AccountIdentifier(testAccountID), testTunnelID)
+	assert.NoError(t, err)
+
+	output := strings.Join(logger.lines, "\n")
+	assert.Len(t, logger.lines, 4)
+	assert.Contains(t, output, "POST /accounts/"+testAccountID+"/cfd_tunnel HTTP/1.1\r\n")
+	assert.Contains(t, output, "[redacted]")
+	assert.NotContains(t, output, "deadbeef")
+	assert.NotContains(t, output, "cloudflare@example.org")
+	assert.NotContains(t, output, "c2VjcmV0LXNlY3JldA==")
+	assert.NotContains(t, output, "ZXhhbXBsZS10b2tlbg==")
+}
@@ -389,3 +389,24 @@ func (e *Error) ErrorMessageContains(s string) bool {
 	}
 	return false
 }
+
+// IsNotFound reports whether err, or any error it wraps, is a *NotFoundError.
+func IsNotFound(err error) bool {
+	var notFoundErr *NotFoundError
+	return errors.As(err, &notFoundErr)
+}
+
+// IsRateLimited reports whether err, or any error it wraps, is a
+// *RatelimitError.
+func IsRateLimited(err error) bool {
+	var ratelimitErr *RatelimitError
+	return errors.As(err, &ratelimitErr)
+}
+
+// IsAuthFailure reports whether err, or any error it wraps, is an
+// *AuthenticationError or *AuthorizationError.
+func IsAuthFailure(err error) bool {
+	var authenticationErr *AuthenticationError
+	var authorizationErr *AuthorizationError
+	return errors.As(err, &authenticationErr) || errors.As(err, &authorizationErr)
+}
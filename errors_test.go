@@ -1,6 +1,7 @@
 package cloudflare
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -50,3 +51,23 @@ func TestError_Error(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorHelpers(t *testing.T) {
+	notFound := &NotFoundError{cloudflareError: &Error{StatusCode: 404}}
+	rateLimited := &RatelimitError{cloudflareError: &Error{StatusCode: 429}}
+	authentication := &AuthenticationError{cloudflareError: &Error{StatusCode: 403}}
+	authorization := &AuthorizationError{cloudflareError: &Error{StatusCode: 401}}
+	request := &RequestError{cloudflareError: &Error{StatusCode: 400}}
+
+	assert.True(t, IsNotFound(notFound))
+	assert.True(t, IsNotFound(fmt.Errorf("wrapped: %w", notFound)))
+	assert.False(t, IsNotFound(request))
+	assert.False(t, IsNotFound(nil))
+
+	assert.True(t, IsRateLimited(rateLimited))
+	assert.False(t, IsRateLimited(notFound))
+
+	assert.True(t, IsAuthFailure(authentication))
+	assert.True(t, IsAuthFailure(fmt.Errorf("wrapped: %w", authorization)))
+	assert.False(t, IsAuthFailure(request))
+}
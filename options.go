@@ -45,11 +45,19 @@ func UsingRateLimit(rps float64) Option {
 func UsingRetryPolicy(maxRetries int, minRetryDelaySecs int, maxRetryDelaySecs int) Option {
 	// seconds is very granular for a minimum delay - but this is only in case of failure
 	return func(api *API) error {
-		api.retryPolicy = RetryPolicy{
-			MaxRetries:    maxRetries,
-			MinRetryDelay: time.Duration(minRetryDelaySecs) * time.Second,
-			MaxRetryDelay: time.Duration(maxRetryDelaySecs) * time.Second,
-		}
+		api.retryPolicy.MaxRetries = maxRetries
+		api.retryPolicy.MinRetryDelay = time.Duration(minRetryDelaySecs) * time.Second
+		api.retryPolicy.MaxRetryDelay = time.Duration(maxRetryDelaySecs) * time.Second
+		return nil
+	}
+}
+
+// UsingRetryableStatusCodes replaces the response status codes which are
+// retried according to the retry policy. By default 429 and all 5xx responses
+// are retried.
+func UsingRetryableStatusCodes(codes ...int) Option {
+	return func(api *API) error {
+		api.retryPolicy.RetryableStatusCodes = codes
 		return nil
 	}
 }
@@ -63,6 +71,57 @@ func UsingLogger(logger Logger) Option {
 	}
 }
 
+// UsingRequestHook can be set to be notified of every request made by this
+// API instance, for example to record metrics. By default no hook is called.
+func UsingRequestHook(hook RequestHook) Option {
+	return func(api *API) error {
+		api.requestHook = hook
+		return nil
+	}
+}
+
+// UsingTracer can be set to trace calls made by this API instance, such as the
+// tunnel route methods, and to propagate the trace context in the request
+// headers. By default nothing is traced.
+func UsingTracer(tracer Tracer) Option {
+	return func(api *API) error {
+		api.tracer = tracer
+		return nil
+	}
+}
+
+// UsingStrictDecoding makes responses containing fields this library does not
+// model an error, which is useful in tests to detect changes to the API early.
+// It currently applies to the tunnel route methods. By default unknown fields
+// are ignored.
+func UsingStrictDecoding(strict bool) Option {
+	return func(api *API) error {
+		api.strictDecoding = strict
+		return nil
+	}
+}
+
+// UsingAccountID sets the account used by the tunnel route methods when they
+// are passed a *ResourceContainer without an identifier, saving single
+// account users from passing it on every call. An account passed explicitly
+// is always used instead.
+func UsingAccountID(accountID string) Option {
+	return func(api *API) error {
+		api.defaultAccountID = accountID
+		return nil
+	}
+}
+
+// UsingAccountRouteRoot replaces the "accounts" route namespace used by the
+// tunnel route methods, for deployments that serve accounts under a different
+// path segment. By default AccountRouteRoot is used.
+func UsingAccountRouteRoot(root RouteRoot) Option {
+	return func(api *API) error {
+		api.accountRouteRoot = root
+		return nil
+	}
+}
+
 // UserAgent can be set if you want to send a software name and version for HTTP access logs.
 // It is recommended to set it in order to help future Customer Support diagnostics
 // and prevent collateral damage by sharing generic User-Agent string with abusive users.
@@ -74,6 +133,18 @@ func UserAgent(userAgent string) Option {
 	}
 }
 
+// UserAgentPrefix prepends prefix to the User-Agent, keeping the default
+// cloudflare-go version so that both the application and the library version
+// can be identified. E.g. "my-software/1.2.3 cloudflare-go/v4". The prefix is
+// applied when each request is made, so it is also kept in front of a
+// User-Agent set with the UserAgent option. An empty prefix is ignored.
+func UserAgentPrefix(prefix string) Option {
+	return func(api *API) error {
+		api.userAgentPrefix = prefix
+		return nil
+	}
+}
+
 // BaseURL allows you to override the default HTTP base URL used for API calls.
 func BaseURL(baseURL string) Option {
 	return func(api *API) error {
@@ -89,6 +160,17 @@ func Debug(debug bool) Option {
 	}
 }
 
+// UsingDebugLogger enables Debug and writes the dumped requests and responses
+// to logger rather than the standard logger. Credentials, tunnel secrets and
+// tokens are redacted from the output.
+func UsingDebugLogger(logger Logger) Option {
+	return func(api *API) error {
+		api.Debug = true
+		api.debugLogger = logger
+		return nil
+	}
+}
+
 // parseOptions parses the supplied options functions and returns a configured
 // *API instance.
 func (api *API) parseOptions(opts ...Option) error {
@@ -112,3 +112,17 @@ func AccountIdentifier(id string) *ResourceContainer {
 		Type:       AccountType,
 	}
 }
+
+// withDefaultAccount returns rc, unless it has no identifier in which case the
+// default account set using UsingAccountID is returned, if any.
+func (api *API) withDefaultAccount(rc *ResourceContainer) *ResourceContainer {
+	if (rc == nil || rc.Identifier == "") && api.defaultAccountID != "" {
+		return AccountIdentifier(api.defaultAccountID)
+	}
+
+	if rc == nil {
+		return AccountIdentifier("")
+	}
+
+	return rc
+}
@@ -39,6 +39,14 @@ func (s *TunnelDuration) UnmarshalJSON(data []byte) error {
 // parameters.
 var ErrMissingTunnelID = errors.New("required missing tunnel ID")
 
+// ErrTunnelNotFound is for when the tunnel a route is being assigned to does
+// not exist.
+var ErrTunnelNotFound = errors.New("tunnel not found")
+
+// ErrTunnelDNSConflict is for when the hostname being routed to a tunnel
+// already has a DNS record that points elsewhere.
+var ErrTunnelDNSConflict = errors.New("hostname already has a conflicting DNS record")
+
 // Tunnel is the struct definition of a tunnel.
 type Tunnel struct {
 	ID             string             `json:"id,omitempty"`
@@ -216,6 +224,11 @@ type TunnelConfigurationParams struct {
 	Config   TunnelConfiguration `json:"config,omitempty"`
 }
 
+type UpdateTunnelWarpRoutingParams struct {
+	TunnelID string
+	Enabled  bool
+}
+
 type TunnelListParams struct {
 	Name          string     `url:"name,omitempty"`
 	UUID          string     `url:"uuid,omitempty"` // the tunnel ID
@@ -282,7 +295,7 @@ func (api *API) GetTunnel(ctx context.Context, rc *ResourceContainer, tunnelID s
 	}
 
 	if tunnelID == "" {
-		return Tunnel{}, errors.New("missing tunnel ID")
+		return Tunnel{}, ErrMissingTunnelID
 	}
 
 	uri := fmt.Sprintf("/accounts/%s/cfd_tunnel/%s", rc.Identifier, tunnelID)
@@ -432,6 +445,29 @@ func (api *API) GetTunnelConfiguration(ctx context.Context, rc *ResourceContaine
 	return tunnelDetails, nil
 }
 
+// UpdateTunnelWarpRouting enables or disables WARP routing for a tunnel,
+// leaving the rest of its configuration untouched. WARP routing is a property
+// of the tunnel rather than of individual routes, so it applies to every
+// private network route of the tunnel. Only remotely managed tunnels can be
+// configured through the API, and WARP clients must also be enrolled with the
+// Zero Trust organization of the account for the routes to be reachable.
+//
+// API reference: https://api.cloudflare.com/#cloudflare-tunnel-configuration-properties
+func (api *API) UpdateTunnelWarpRouting(ctx context.Context, rc *ResourceContainer, params UpdateTunnelWarpRoutingParams) (TunnelConfigurationResult, error) {
+	current, err := api.GetTunnelConfiguration(ctx, rc, params.TunnelID)
+	if err != nil {
+		return TunnelConfigurationResult{}, err
+	}
+
+	config := current.Config
+	config.WarpRouting = &WarpRoutingConfig{Enabled: params.Enabled}
+
+	return api.UpdateTunnelConfiguration(ctx, rc, TunnelConfigurationParams{
+		TunnelID: params.TunnelID,
+		Config:   config,
+	})
+}
+
 // ListTunnelConnections gets all connections on a tunnel.
 //
 // API reference: https://api.cloudflare.com/#cloudflare-tunnel-list-cloudflare-tunnel-connections
@@ -463,6 +499,14 @@ func (api *API) ListTunnelConnections(ctx context.Context, rc *ResourceContainer
 //
 // API reference: https://api.cloudflare.com/#cloudflare-tunnel-delete-cloudflare-tunnel
 func (api *API) DeleteTunnel(ctx context.Context, rc *ResourceContainer, tunnelID string) error {
+	if rc.Identifier == "" {
+		return ErrMissingAccountID
+	}
+
+	if tunnelID == "" {
+		return ErrMissingTunnelID
+	}
+
 	uri := fmt.Sprintf("/accounts/%s/cfd_tunnel/%s", rc.Identifier, tunnelID)
 
 	res, err := api.makeRequestContext(ctx, http.MethodDelete, uri, nil)
@@ -509,6 +553,12 @@ func (api *API) CleanupTunnelConnections(ctx context.Context, rc *ResourceContai
 
 // GetTunnelToken that allows to run a tunnel.
 //
+// The token is returned base64 encoded, exactly as expected by
+// `cloudflared tunnel run --token`. Tokens are only issued for remotely
+// managed tunnels (those created with ConfigSrc "cloudflare"); locally
+// managed tunnels authenticate using their credentials file instead and the
+// API returns an error for them.
+//
 // API reference: https://api.cloudflare.com/#cloudflare-tunnel-get-cloudflare-tunnel-token
 func (api *API) GetTunnelToken(ctx context.Context, rc *ResourceContainer, tunnelID string) (string, error) {
 	if rc.Identifier == "" {
@@ -516,7 +566,7 @@ func (api *API) GetTunnelToken(ctx context.Context, rc *ResourceContainer, tunne
 	}
 
 	if tunnelID == "" {
-		return "", errors.New("missing tunnel ID")
+		return "", ErrMissingTunnelID
 	}
 
 	uri := fmt.Sprintf("/accounts/%s/cfd_tunnel/%s/token", rc.Identifier, tunnelID)
@@ -534,3 +584,75 @@ func (api *API) GetTunnelToken(ctx context.Context, rc *ResourceContainer, tunne
 
 	return tunnelTokenResponse.Result, nil
 }
+
+// RouteTunnelDNSParams holds the parameters for routing a hostname to a tunnel
+// using RouteTunnelDNS.
+type RouteTunnelDNSParams struct {
+	Hostname string
+	TunnelID string
+	// ErrorOnConflict returns ErrTunnelDNSConflict rather than replacing an
+	// existing record for the hostname that does not point at the tunnel.
+	ErrorOnConflict bool
+}
+
+// TunnelHostname returns the hostname which DNS records should point at to
+// send traffic to a tunnel.
+func TunnelHostname(tunnelID string) string {
+	return tunnelID + ".cfargotunnel.com"
+}
+
+// RouteTunnelDNS sends traffic for a hostname in the zone to a tunnel by
+// creating a proxied CNAME record pointing at TunnelHostname. An existing
+// record for the hostname is updated in place, unless ErrorOnConflict is set,
+// and is left as is when it already points at the tunnel. An unproxied CNAME
+// record already pointing at the tunnel is never a conflict; it is updated to
+// be proxied.
+func (api *API) RouteTunnelDNS(ctx context.Context, rc *ResourceContainer, params RouteTunnelDNSParams) (DNSRecord, error) {
+	if rc.Identifier == "" {
+		return DNSRecord{}, ErrMissingZoneID
+	}
+
+	if params.Hostname == "" {
+		return DNSRecord{}, ErrMissingHostname
+	}
+
+	if params.TunnelID == "" {
+		return DNSRecord{}, ErrMissingTunnelID
+	}
+
+	target := TunnelHostname(params.TunnelID)
+	records, _, err := api.ListDNSRecords(ctx, rc, ListDNSRecordsParams{Name: params.Hostname})
+	if err != nil {
+		return DNSRecord{}, err
+	}
+
+	switch {
+	case len(records) == 0:
+		return api.CreateDNSRecord(ctx, rc, CreateDNSRecordParams{
+			Type:    "CNAME",
+			Name:    params.Hostname,
+			Content: target,
+			Proxied: BoolPtr(true),
+		})
+	case len(records) > 1:
+		// A CNAME cannot coexist with other records so several records,
+		// such as a pair of A and AAAA records, cannot be replaced in place.
+		return DNSRecord{}, fmt.Errorf("%w: %s", ErrTunnelDNSConflict, params.Hostname)
+	case records[0].Type == "CNAME" && records[0].Content == target:
+		if records[0].Proxied != nil && *records[0].Proxied {
+			return records[0], nil
+		}
+	case params.ErrorOnConflict:
+		return DNSRecord{}, fmt.Errorf("%w: %s", ErrTunnelDNSConflict, params.Hostname)
+	}
+
+	return api.UpdateDNSRecord(ctx, rc, UpdateDNSRecordParams{
+		ID:      records[0].ID,
+		Type:    "CNAME",
+		Name:    params.Hostname,
+		Content: target,
+		Proxied: BoolPtr(true),
+		Comment: records[0].Comment,
+		Tags:    records[0].Tags,
+	})
+}
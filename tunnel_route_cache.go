@@ -0,0 +1,187 @@
+package cloudflare
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TunnelRouteCacheOptions configures a TunnelRouteCache.
+type TunnelRouteCacheOptions struct {
+	// TTL is how long listed routes are served from the cache before being
+	// listed again. Zero lists the routes on every call.
+	TTL time.Duration
+	// InvalidateOnWrite discards the cached routes whenever a route in the
+	// same account is created, updated or deleted using the API the cache was
+	// created from.
+	InvalidateOnWrite bool
+}
+
+// TunnelRouteCache serves the routes matching a set of list params from
+// memory, listing them again once the TTL expires. It is safe for concurrent
+// use; concurrent callers finding the cache expired share a single listing.
+type TunnelRouteCache struct {
+	api     *API
+	rc      *ResourceContainer
+	params  TunnelRoutesListParams
+	options TunnelRouteCacheOptions
+
+	// refreshMu serialises listing the routes so that it can happen without
+	// holding mu, allowing the cache to be invalidated in the meantime.
+	refreshMu sync.Mutex
+
+	mu         sync.Mutex
+	routes     []TunnelRoute
+	fetchedAt  time.Time
+	generation int
+}
+
+// NewTunnelRouteCache returns a cache of the routes matching params. Nothing
+// is listed until the cache is first read. Caches using InvalidateOnWrite
+// should be closed once no longer needed.
+func (api *API) NewTunnelRouteCache(rc *ResourceContainer, params TunnelRoutesListParams, options TunnelRouteCacheOptions) *TunnelRouteCache {
+	rc = api.withDefaultAccount(rc)
+
+	c := &TunnelRouteCache{api: api, rc: rc, params: params, options: options}
+	if options.InvalidateOnWrite && api.tunnelRouteCaches != nil {
+		api.tunnelRouteCaches.add(c)
+	}
+
+	return c
+}
+
+// All returns every cached route, listing them first if the cache is empty or
+// has expired.
+func (c *TunnelRouteCache) All(ctx context.Context) ([]TunnelRoute, error) {
+	routes, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]TunnelRoute(nil), routes...), nil
+}
+
+// GetByNetwork returns the cached route for the network in the virtual
+// network, and whether there is one.
+func (c *TunnelRouteCache) GetByNetwork(ctx context.Context, network, virtualNetworkID string) (TunnelRoute, bool, error) {
+	routes, err := c.load(ctx)
+	if err != nil {
+		return TunnelRoute{}, false, err
+	}
+
+	key := TunnelRouteKey{Network: network, VirtualNetworkID: virtualNetworkID}
+	for _, route := range routes {
+		if route.Key() == key {
+			return route, true, nil
+		}
+	}
+
+	return TunnelRoute{}, false, nil
+}
+
+// Invalidate discards the cached routes so that the next read lists them
+// again.
+func (c *TunnelRouteCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.routes = nil
+	c.fetchedAt = time.Time{}
+	c.generation++
+}
+
+// Close stops the cache from being invalidated on writes. The cache can still
+// be read afterwards.
+func (c *TunnelRouteCache) Close() {
+	if c.api.tunnelRouteCaches != nil {
+		c.api.tunnelRouteCaches.remove(c)
+	}
+}
+
+// load returns the cached routes, listing them if needed. The returned slice
+// must not be modified.
+func (c *TunnelRouteCache) load(ctx context.Context) ([]TunnelRoute, error) {
+	if routes, ok := c.fresh(); ok {
+		return routes, nil
+	}
+
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	// another caller may have refreshed the cache while we were waiting
+	if routes, ok := c.fresh(); ok {
+		return routes, nil
+	}
+
+	c.mu.Lock()
+	generation := c.generation
+	c.mu.Unlock()
+
+	routes, _, err := c.api.ListTunnelRoutesAll(ctx, c.rc, c.params)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// only keep the routes if the cache wasn't invalidated while listing them,
+	// as they may not include the write that invalidated it
+	if c.generation == generation {
+		c.routes = routes
+		c.fetchedAt = time.Now()
+	}
+
+	return routes, nil
+}
+
+// fresh returns the cached routes if they have not expired.
+func (c *TunnelRouteCache) fresh() ([]TunnelRoute, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.fetchedAt.IsZero() || time.Since(c.fetchedAt) >= c.options.TTL {
+		return nil, false
+	}
+
+	return c.routes, true
+}
+
+// tunnelRouteCacheSet holds the caches to invalidate when routes are written.
+type tunnelRouteCacheSet struct {
+	mu     sync.Mutex
+	caches map[*TunnelRouteCache]struct{}
+}
+
+func (s *tunnelRouteCacheSet) add(c *TunnelRouteCache) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.caches == nil {
+		s.caches = make(map[*TunnelRouteCache]struct{})
+	}
+	s.caches[c] = struct{}{}
+}
+
+func (s *tunnelRouteCacheSet) remove(c *TunnelRouteCache) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.caches, c)
+}
+
+// invalidate discards the routes of every cache for the account.
+func (s *tunnelRouteCacheSet) invalidate(accountID string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for c := range s.caches {
+		if c.rc.Identifier == accountID {
+			c.Invalidate()
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package cloudflare
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTunnelRouteCache(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var mu sync.Mutex
+	listed := 0
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		mu.Lock()
+		listed++
+		mu.Unlock()
+
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": [
+			  {"id": "a", "network": "10.0.0.0/16"},
+			  {"id": "b", "network": "10.0.0.0/16", "virtual_network_id": "vnet"}
+			]
+		  }`)
+	})
+
+	cache := client.NewTunnelRouteCache(AccountIdentifier(testAccountID), TunnelRoutesListParams{}, TunnelRouteCacheOptions{TTL: time.Hour})
+	defer cache.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			routes, err := cache.All(context.Background())
+			assert.NoError(t, err)
+			assert.Len(t, routes, 2)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, listed)
+
+	route, ok, err := cache.GetByNetwork(context.Background(), "10.0.0.0/16", "vnet")
+	if assert.NoError(t, err) && assert.True(t, ok) {
+		assert.Equal(t, "b", route.ID)
+	}
+
+	_, ok, err = cache.GetByNetwork(context.Background(), "10.1.0.0/16", "")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 1, listed)
+
+	cache.Invalidate()
+	_, err = cache.All(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, listed)
+}
+
+func TestTunnelRouteCache_TTL(t *testing.T) {
+	setup()
+	defer teardown()
+
+	listed := 0
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		listed++
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": [{"id": "a", "network": "10.0.0.0/16"}]}`)
+	})
+
+	cache := client.NewTunnelRouteCache(AccountIdentifier(testAccountID), TunnelRoutesListParams{}, TunnelRouteCacheOptions{TTL: 10 * time.Millisecond})
+
+	_, err := cache.All(context.Background())
+	assert.NoError(t, err)
+	_, err = cache.All(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, listed)
+
+	time.Sleep(20 * time.Millisecond)
+	_, err = cache.All(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, listed)
+}
+
+func TestTunnelRouteCache_InvalidateOnWrite(t *testing.T) {
+	setup()
+	defer teardown()
+
+	listed := 0
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		listed++
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": []}`)
+	})
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/10.0.0.0/16", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": {"network": "10.0.0.0/16", "tunnel_id": "%s"}}`, testTunnelID)
+	})
+
+	cache := client.NewTunnelRouteCache(AccountIdentifier(testAccountID), TunnelRoutesListParams{}, TunnelRouteCacheOptions{TTL: time.Hour, InvalidateOnWrite: true})
+	other := client.NewTunnelRouteCache(AccountIdentifier("other"), TunnelRoutesListParams{}, TunnelRouteCacheOptions{TTL: time.Hour, InvalidateOnWrite: true})
+	defer other.Close()
+
+	_, err := cache.All(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, listed)
+
+	_, err = client.CreateTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesCreateParams{Network: "10.0.0.0/16", TunnelID: testTunnelID})
+	assert.NoError(t, err)
+	_, err = cache.All(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, listed)
+
+	_, err = client.DeleteTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesDeleteParams{Network: "10.0.0.0/16"})
+	assert.NoError(t, err)
+	_, err = cache.All(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, listed)
+
+	cache.Close()
+	_, err = client.UpdateTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesUpdateParams{Network: "10.0.0.0/16", Comment: "updated"})
+	assert.NoError(t, err)
+	_, err = cache.All(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, listed)
+}
@@ -0,0 +1,149 @@
+package cloudflare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+// TunnelRoutesSnapshotVersion is the version of the snapshot format written by
+// SnapshotTunnelRoutes. It is increased whenever the format changes in a way
+// older versions of this library cannot restore.
+const TunnelRoutesSnapshotVersion = 1
+
+// ErrUnsupportedSnapshotVersion is for when a snapshot was written in a format
+// this version of the library does not understand.
+var ErrUnsupportedSnapshotVersion = errors.New("unsupported tunnel routes snapshot version")
+
+// TunnelRoutesSnapshot is the JSON document written by SnapshotTunnelRoutes.
+type TunnelRoutesSnapshot struct {
+	Version   int           `json:"version"`
+	AccountID string        `json:"account_id"`
+	CreatedAt time.Time     `json:"created_at"`
+	Routes    []TunnelRoute `json:"routes"`
+}
+
+// SnapshotTunnelRoutes writes every active route in the account to w as a
+// TunnelRoutesSnapshot, ordered by network, so that the routing table can be
+// backed up and later restored using RestoreTunnelRoutesFromSnapshot.
+func (api *API) SnapshotTunnelRoutes(ctx context.Context, rc *ResourceContainer, w io.Writer) error {
+	rc = api.withDefaultAccount(rc)
+
+	if rc.Identifier == "" {
+		return ErrMissingAccountID
+	}
+
+	routes, _, err := api.ListTunnelRoutesAll(ctx, rc, TunnelRoutesListParams{IsDeleted: BoolPtr(false)})
+	if err != nil {
+		return err
+	}
+
+	sort.Stable(ByNetwork(routes))
+	for i := range routes {
+		routes[i] = utcTunnelRoute(routes[i])
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(TunnelRoutesSnapshot{
+		Version:   TunnelRoutesSnapshotVersion,
+		AccountID: rc.Identifier,
+		CreatedAt: time.Now().UTC(),
+		Routes:    routes,
+	})
+}
+
+// RestoreTunnelRoutesFromSnapshot recreates the routes of a snapshot written by
+// SnapshotTunnelRoutes. Routes are matched as in DiffTunnelRoutes: missing
+// routes are created, routes whose tunnel or comment differ are updated and
+// routes which already match are skipped. As with ReplaceTunnelRoutes, an
+// existing comment is left in place when the snapshot has none. Routes not in
+// the snapshot are left in place; ReplaceTunnelRoutes can be used to remove
+// them. The snapshot may be restored into a different account than it was
+// taken from.
+//
+// Every route is attempted even if some fail, in which case the changes made
+// are returned along with a *TunnelRouteBulkError. Result.Deleted is always
+// empty.
+func (api *API) RestoreTunnelRoutesFromSnapshot(ctx context.Context, rc *ResourceContainer, r io.Reader) (TunnelRoutesReplaceResult, error) {
+	rc = api.withDefaultAccount(rc)
+
+	var result TunnelRoutesReplaceResult
+
+	if rc.Identifier == "" {
+		return result, ErrMissingAccountID
+	}
+
+	var snapshot TunnelRoutesSnapshot
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return result, fmt.Errorf("%s: %w", errUnmarshalError, err)
+	}
+
+	if snapshot.Version < 1 || snapshot.Version > TunnelRoutesSnapshotVersion {
+		return result, fmt.Errorf("%w: %d", ErrUnsupportedSnapshotVersion, snapshot.Version)
+	}
+
+	for _, route := range snapshot.Routes {
+		if err := validateTunnelRouteNetwork(route.Network); err != nil {
+			return result, err
+		}
+	}
+
+	current, _, err := api.ListTunnelRoutesAll(ctx, rc, TunnelRoutesListParams{IsDeleted: BoolPtr(false)})
+	if err != nil {
+		return result, err
+	}
+
+	toCreate, toUpdate, _ := DiffTunnelRoutes(snapshot.Routes, current)
+
+	currentByKey := make(map[TunnelRouteKey]TunnelRoute, len(current))
+	for _, route := range current {
+		currentByKey[route.Key()] = route
+	}
+
+	var errs []error
+	for _, route := range toCreate {
+		created, err := api.CreateTunnelRoute(ctx, rc, TunnelRoutesCreateParams{
+			Network:          route.Network,
+			TunnelID:         route.TunnelID,
+			Comment:          route.Comment,
+			VirtualNetworkID: route.VirtualNetworkID,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", route.Network, err))
+			continue
+		}
+		result.Created = append(result.Created, created)
+	}
+
+	for _, route := range toUpdate {
+		// an empty comment cannot be sent to clear an existing one, so a
+		// route whose tunnel is unchanged already matches as far as it can
+		if route.Comment == "" && route.TunnelID == currentByKey[route.Key()].TunnelID {
+			continue
+		}
+
+		updated, err := api.UpdateTunnelRoute(ctx, rc, TunnelRoutesUpdateParams{
+			Network:          route.Network,
+			TunnelID:         route.TunnelID,
+			Comment:          route.Comment,
+			VirtualNetworkID: route.VirtualNetworkID,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", route.Network, err))
+			continue
+		}
+		result.Updated = append(result.Updated, updated)
+	}
+
+	if len(errs) > 0 {
+		return result, &TunnelRouteBulkError{Errors: errs}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,189 @@
+package cloudflare
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotTunnelRoutes(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		assert.Equal(t, "false", r.URL.Query().Get("is_deleted"))
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": [
+			  {"id": "b", "network": "10.1.0.0/16", "tunnel_id": "tunnel", "comment": "b"},
+			  {"id": "a", "network": "9.0.0.0/8", "tunnel_id": "tunnel", "virtual_network_id": "vnet"}
+			]
+		  }`)
+	})
+
+	var buf bytes.Buffer
+	err := client.SnapshotTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), &buf)
+	assert.NoError(t, err)
+
+	var snapshot TunnelRoutesSnapshot
+	if assert.NoError(t, json.Unmarshal(buf.Bytes(), &snapshot)) {
+		assert.Equal(t, TunnelRoutesSnapshotVersion, snapshot.Version)
+		assert.Equal(t, testAccountID, snapshot.AccountID)
+		assert.False(t, snapshot.CreatedAt.IsZero())
+		assert.Equal(t, []TunnelRoute{
+			{ID: "a", Network: "9.0.0.0/8", TunnelID: "tunnel", VirtualNetworkID: "vnet"},
+			{ID: "b", Network: "10.1.0.0/16", TunnelID: "tunnel", Comment: "b"},
+		}, snapshot.Routes)
+	}
+
+	assert.ErrorIs(t, client.SnapshotTunnelRoutes(context.Background(), AccountIdentifier(""), &buf), ErrMissingAccountID)
+}
+
+func TestRestoreTunnelRoutesFromSnapshot(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": [
+			  {"id": "1", "network": "10.0.0.0/16", "tunnel_id": "tunnel", "comment": "same"},
+			  {"id": "2", "network": "10.1.0.0/16", "tunnel_id": "other"},
+			  {"id": "3", "network": "10.9.0.0/16", "tunnel_id": "tunnel"}
+			]
+		  }`)
+	})
+
+	calls := map[string]string{}
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/", func(w http.ResponseWriter, r *http.Request) {
+		network := strings.TrimPrefix(r.URL.Path, "/accounts/"+testAccountID+"/teamnet/routes/network/")
+		calls[network] = r.Method
+		w.Header().Set("content-type", "application/json")
+		if network == "10.3.0.0/16" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"success": false, "errors": [{"code": 1000, "message": "bad route"}], "messages": [], "result": null}`)
+			return
+		}
+		fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": {"network": "%s", "tunnel_id": "tunnel"}}`, network)
+	})
+
+	snapshot := `{
+		"version": 1,
+		"account_id": "another-account",
+		"created_at": "2023-01-01T00:00:00Z",
+		"routes": [
+		  {"network": "10.0.0.0/16", "tunnel_id": "tunnel", "comment": "same"},
+		  {"network": "10.1.0.0/16", "tunnel_id": "tunnel"},
+		  {"network": "10.2.0.0/16", "tunnel_id": "tunnel"},
+		  {"network": "10.3.0.0/16", "tunnel_id": "tunnel"}
+		]
+	  }`
+
+	result, err := client.RestoreTunnelRoutesFromSnapshot(context.Background(), AccountIdentifier(testAccountID), strings.NewReader(snapshot))
+
+	var bulkErr *TunnelRouteBulkError
+	if assert.ErrorAs(t, err, &bulkErr) {
+		assert.Len(t, bulkErr.Errors, 1)
+		assert.Contains(t, bulkErr.Error(), "10.3.0.0/16")
+	}
+	assert.Equal(t, []TunnelRoute{{Network: "10.2.0.0/16", TunnelID: "tunnel"}}, result.Created)
+	assert.Equal(t, []TunnelRoute{{Network: "10.1.0.0/16", TunnelID: "tunnel"}}, result.Updated)
+	assert.Empty(t, result.Deleted)
+	assert.Equal(t, map[string]string{
+		"10.1.0.0/16": http.MethodPatch,
+		"10.2.0.0/16": http.MethodPost,
+		"10.3.0.0/16": http.MethodPost,
+	}, calls)
+}
+
+func TestRestoreTunnelRoutesFromSnapshot_Invalid(t *testing.T) {
+	setup()
+	defer teardown()
+
+	_, err := client.RestoreTunnelRoutesFromSnapshot(context.Background(), AccountIdentifier(testAccountID), strings.NewReader(`{"version": 2, "routes": []}`))
+	assert.ErrorIs(t, err, ErrUnsupportedSnapshotVersion)
+
+	_, err = client.RestoreTunnelRoutesFromSnapshot(context.Background(), AccountIdentifier(testAccountID), strings.NewReader(`{"routes": []}`))
+	assert.ErrorIs(t, err, ErrUnsupportedSnapshotVersion)
+
+	_, err = client.RestoreTunnelRoutesFromSnapshot(context.Background(), AccountIdentifier(testAccountID), strings.NewReader(`{"version": 1, "routes": [{"network": "10.0.0.1"}]}`))
+	assert.ErrorIs(t, err, ErrInvalidNetworkCIDR)
+
+	_, err = client.RestoreTunnelRoutesFromSnapshot(context.Background(), AccountIdentifier(testAccountID), strings.NewReader(`not json`))
+	assert.Error(t, err)
+
+	_, err = client.RestoreTunnelRoutesFromSnapshot(context.Background(), AccountIdentifier(""), strings.NewReader(`{}`))
+	assert.ErrorIs(t, err, ErrMissingAccountID)
+}
+
+func TestRestoreTunnelRoutesFromSnapshot_Idempotent(t *testing.T) {
+	setup()
+	defer teardown()
+
+	// the live route has a comment the snapshot lacks, which a PATCH cannot
+	// clear, and a tunnel which the snapshot changes
+	current := map[string]string{"10.0.0.0/16": "tunnel", "10.1.0.0/16": "old"}
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": [
+			  {"network": "10.0.0.0/16", "tunnel_id": "%s", "comment": "live comment"},
+			  {"network": "10.1.0.0/16", "tunnel_id": "%s", "comment": "live comment"}
+			]
+		  }`, current["10.0.0.0/16"], current["10.1.0.0/16"])
+	})
+
+	patches := 0
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method, "Expected method 'PATCH', got %s", r.Method)
+		network := strings.TrimPrefix(r.URL.Path, "/accounts/"+testAccountID+"/teamnet/routes/network/")
+		patches++
+
+		var params TunnelRoutesUpdateParams
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		current[network] = params.TunnelID
+
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": {"network": "%s", "tunnel_id": "%s", "comment": "live comment"}}`, network, params.TunnelID)
+	})
+
+	snapshot := `{
+		"version": 1,
+		"routes": [
+		  {"network": "10.0.0.0/16", "tunnel_id": "tunnel"},
+		  {"network": "10.1.0.0/16", "tunnel_id": "tunnel"}
+		]
+	  }`
+
+	result, err := client.RestoreTunnelRoutesFromSnapshot(context.Background(), AccountIdentifier(testAccountID), strings.NewReader(snapshot))
+	if assert.NoError(t, err) {
+		assert.Empty(t, result.Created)
+		if assert.Len(t, result.Updated, 1) {
+			assert.Equal(t, "10.1.0.0/16", result.Updated[0].Network)
+		}
+	}
+	assert.Equal(t, 1, patches)
+
+	result, err = client.RestoreTunnelRoutesFromSnapshot(context.Background(), AccountIdentifier(testAccountID), strings.NewReader(snapshot))
+	if assert.NoError(t, err) {
+		assert.Empty(t, result.Created)
+		assert.Empty(t, result.Updated)
+	}
+	assert.Equal(t, 1, patches)
+}
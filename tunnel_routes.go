@@ -1,44 +1,322 @@
 package cloudflare
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/csv"
 	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/goccy/go-json"
+	"github.com/google/go-querystring/query"
 )
 
 var (
-	ErrMissingNetwork      = errors.New("missing required network parameter")
-	ErrInvalidNetworkValue = errors.New("invalid IP parameter. Cannot use CIDR ranges for this endpoint.")
+	ErrMissingNetwork          = errors.New("missing required network parameter")
+	ErrInvalidNetworkValue     = errors.New("invalid IP parameter. Cannot use CIDR ranges for this endpoint.")
+	ErrInvalidNetworkCIDR      = errors.New("invalid network parameter, must be an IPv4 or IPv6 CIDR range")
+	ErrTunnelRouteExists       = errors.New("tunnel route already exists")
+	ErrMissingTunnelRouteID    = errors.New("required missing tunnel route ID")
+	ErrTunnelRouteNotFound     = errors.New("tunnel route not found")
+	ErrDeleteNotConfirmed      = errors.New("deleting tunnel routes by filter requires Confirm to be set")
+	ErrTunnelRouteConflict     = errors.New("tunnel route was modified since it was fetched")
+	ErrDuplicateTunnelRoute    = errors.New("duplicate tunnel route")
+	ErrInvalidWatchInterval    = errors.New("watch interval must be positive")
+	ErrInvalidPerPage          = fmt.Errorf("invalid per page value, must be between 1 and %d", listTunnelRoutesMaxPageSize)
+	ErrPaginationLimitExceeded = errors.New("tunnel routes pagination limit exceeded")
+	ErrInvalidMaxPages         = errors.New("invalid max pages value, must not be negative")
+	ErrMultipleTunnelIDs       = errors.New("listing a single page of tunnel routes supports at most one tunnel ID")
+	ErrTooManyDeletions        = errors.New("replacing tunnel routes would delete more routes than allowed")
 )
 
-// TunnelRoute is the full record for a route.
+// ErrTunnelRoutePermission is returned alongside the *AuthenticationError of a
+// 403 response to a route operation, which is almost always caused by an API
+// token lacking the Cloudflare Tunnel edit permission. VerifyAPIToken can be
+// used to check that the token itself is valid.
+var ErrTunnelRoutePermission = errors.New("API token is missing the Cloudflare Tunnel edit permission needed to manage routes")
+
+const (
+	// listTunnelRoutesDefaultPageSize is used when no PerPage is given.
+	listTunnelRoutesDefaultPageSize = 100
+	// listTunnelRoutesMaxPageSize is the largest PerPage the API accepts.
+	listTunnelRoutesMaxPageSize = 1000
+	// listTunnelRoutesDefaultMaxPages is used when no MaxPages is given.
+	listTunnelRoutesDefaultMaxPages = 10000
+)
+
+// TunnelRouteCommentMaxLength is the maximum number of characters in the
+// comment of a route accepted by the API.
+const TunnelRouteCommentMaxLength = 100
+
+// ErrCommentTooLong is for when the comment of a route is longer than
+// TunnelRouteCommentMaxLength.
+var ErrCommentTooLong = fmt.Errorf("comment must be at most %d characters", TunnelRouteCommentMaxLength)
+
+// Error codes returned by the teamnet routes API which can be checked for using
+// IsTunnelRouteError. Failures which have their own HTTP status, such as a
+// missing tunnel or a token lacking permission, are instead reported as a
+// *NotFoundError or *AuthenticationError respectively, the latter also
+// matching ErrTunnelRoutePermission.
+//
+// See: https://developers.cloudflare.com/api/operations/tunnel-route-create-a-tunnel-route
+const (
+	// TunnelRouteErrorCodeExists is returned when a route for the network
+	// already exists.
+	TunnelRouteErrorCodeExists = 1014
+)
+
+// TunnelRoute is the full record for a route. The API does not associate
+// routes with a data center; the colos serving a route are those its tunnel is
+// connected to, which are reported by ListTunnelConnections.
 type TunnelRoute struct {
-	Network          string     `json:"network"`
-	TunnelID         string     `json:"tunnel_id"`
-	TunnelName       string     `json:"tunnel_name"`
+	ID         string `json:"id"`
+	Network    string `json:"network"`
+	TunnelID   string `json:"tunnel_id"`
+	TunnelName string `json:"tunnel_name"`
+	// TunnelType is the type of the tunnel the route belongs to, such as
+	// TunnelRouteTypeCloudflared or TunnelRouteTypeWarpConnector.
+	TunnelType       string     `json:"tun_type,omitempty"`
 	Comment          string     `json:"comment"`
 	CreatedAt        *time.Time `json:"created_at"`
 	DeletedAt        *time.Time `json:"deleted_at"`
 	VirtualNetworkID string     `json:"virtual_network_id"`
+	// ETag is the entity tag sent by the API alongside the route, if any. It
+	// can be passed as TunnelRoutesUpdateParams.IfMatch to guard against
+	// concurrent modification.
+	ETag string `json:"-"`
+}
+
+// TunnelRouteKey uniquely identifies an active route within an account, as the
+// same network may be routed once in each virtual network.
+type TunnelRouteKey struct {
+	Network          string
+	VirtualNetworkID string
+}
+
+// IsDeleted reports whether the route has been deleted, see DeleteTunnelRoute.
+func (r TunnelRoute) IsDeleted() bool {
+	return r.DeletedAt != nil
+}
+
+// Key returns the key uniquely identifying the route.
+func (r TunnelRoute) Key() TunnelRouteKey {
+	return TunnelRouteKey{Network: r.Network, VirtualNetworkID: r.VirtualNetworkID}
+}
+
+// utcTunnelRoute returns the route with its timestamps in UTC, so that the
+// routes written by ExportTunnelRoutes and SnapshotTunnelRoutes are stable
+// regardless of how the API formatted them.
+func utcTunnelRoute(route TunnelRoute) TunnelRoute {
+	if route.CreatedAt != nil {
+		createdAt := route.CreatedAt.UTC()
+		route.CreatedAt = &createdAt
+	}
+
+	if route.DeletedAt != nil {
+		deletedAt := route.DeletedAt.UTC()
+		route.DeletedAt = &deletedAt
+	}
+
+	return route
+}
+
+func formatTunnelRouteTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+
+	formatted := t.UTC().Format(time.RFC3339Nano)
+	return &formatted
 }
 
+// TunnelRoutesListParams holds the filters and pagination options for listing
+// routes. The API always returns complete route records as it has no support
+// for selecting a subset of fields; for large accounts StreamTunnelRoutes
+// avoids holding every route in memory at once.
 type TunnelRoutesListParams struct {
-	TunnelID         string     `url:"tunnel_id,omitempty"`
-	Comment          string     `url:"comment,omitempty"`
-	IsDeleted        *bool      `url:"is_deleted,omitempty"`
-	NetworkSubset    string     `url:"network_subset,omitempty"`
+	// TunnelID limits the results to routes for the tunnel.
+	TunnelID string `url:"tunnel_id,omitempty"`
+	// TunnelIDs limits the results to routes for any of the given tunnels,
+	// and takes precedence over TunnelID. The API only filters by a single
+	// tunnel, so the methods listing every page, such as ListTunnelRoutesAll,
+	// list the routes of each tunnel in turn and merge the results, dropping
+	// repeated tunnels and any route whose ID has already been seen. Methods
+	// listing a single page, such as ListTunnelRoutes, return
+	// ErrMultipleTunnelIDs when given more than one.
+	TunnelIDs []string `url:"-"`
+	Comment   string   `url:"comment,omitempty"`
+	// IsDeleted limits the results to only deleted routes when true or only
+	// active routes when false. Both are included when nil. Deleted is
+	// usually clearer and takes precedence when set.
+	IsDeleted *bool `url:"is_deleted,omitempty"`
+	// Deleted limits the results by whether routes have been deleted.
+	Deleted TunnelRoutesDeletedFilter `url:"-"`
+	// NetworkSubset limits the results to routes whose network is contained
+	// within, or equal to, this CIDR range; the routes are subsets of it. See
+	// ListTunnelRoutesContainedBy.
+	NetworkSubset string `url:"network_subset,omitempty"`
+	// NetworkSuperset limits the results to routes whose network contains, or
+	// is equal to, this CIDR range; the routes are supersets of it. See
+	// ListTunnelRoutesContaining.
+	//
+	// When both are set only routes satisfying both are listed, those lying
+	// between the two ranges. Setting both to the same range lists only the
+	// routes for exactly that network, see GetTunnelRouteByNetwork.
 	NetworkSuperset  string     `url:"network_superset,omitempty"`
 	ExistedAt        *time.Time `url:"existed_at,omitempty"`
 	VirtualNetworkID string     `url:"virtual_network_id,omitempty"`
+	// TunnelTypes limits the results to routes for tunnels of any of the given
+	// types, such as TunnelRouteTypeCloudflared.
+	TunnelTypes []string `url:"tun_types,comma,omitempty"`
+	// Cursor resumes listing from a cursor previously returned by the API in
+	// the result info. Page based pagination is used when it is empty.
+	Cursor string `url:"cursor,omitempty"`
+	// ExtraParams are passed through as additional query parameters, allowing
+	// filters which are not yet modelled here to be used. They never replace
+	// a parameter set by one of the fields above.
+	ExtraParams map[string]string `url:"-"`
+	// Timeout bounds the whole list operation, including every page fetched.
+	// It is applied on top of any deadline of the context passed in. Zero
+	// means no additional timeout.
+	Timeout time.Duration `url:"-"`
+	// MaxPages bounds the number of pages fetched by the methods which list
+	// every page, such as ListTunnelRoutesAll, guarding against a server
+	// which never stops returning pages. ErrPaginationLimitExceeded is
+	// returned once the limit is reached with pages remaining. Defaults to
+	// 10000 when zero and ErrInvalidMaxPages is returned when negative.
+	MaxPages int `url:"-"`
+	// PaginationOptions selects the page to list. PerPage defaults to 100
+	// when zero and may be at most 1000, the largest page the API allows.
 	PaginationOptions
 }
 
+// withTimeout derives a context bounded by the Timeout, if one is set.
+func (p TunnelRoutesListParams) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.Timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, p.Timeout)
+}
+
+// TunnelRoutesDeletedFilter selects routes by whether they have been deleted.
+type TunnelRoutesDeletedFilter string
+
+const (
+	// TunnelRoutesActive includes only routes which have not been deleted.
+	TunnelRoutesActive TunnelRoutesDeletedFilter = "active"
+	// TunnelRoutesDeleted includes only routes which have been deleted.
+	TunnelRoutesDeleted TunnelRoutesDeletedFilter = "deleted"
+	// TunnelRoutesAll includes routes regardless of whether they have been
+	// deleted.
+	TunnelRoutesAll TunnelRoutesDeletedFilter = "all"
+)
+
+// encodeValues returns the query parameters for the list request before they
+// are encoded.
+func (p TunnelRoutesListParams) encodeValues() url.Values {
+	if len(p.TunnelIDs) == 1 {
+		p.TunnelID = p.TunnelIDs[0]
+	}
+
+	switch p.Deleted {
+	case TunnelRoutesActive:
+		p.IsDeleted = BoolPtr(false)
+	case TunnelRoutesDeleted:
+		p.IsDeleted = BoolPtr(true)
+	case TunnelRoutesAll:
+		p.IsDeleted = nil
+	}
+
+	v, _ := query.Values(p)
+	for key, value := range p.ExtraParams {
+		if _, ok := v[key]; !ok {
+			v.Set(key, value)
+		}
+	}
+
+	return v
+}
+
+// encode returns the query string for the list request. Keys are sorted so the
+// output is deterministic.
+func (p TunnelRoutesListParams) encode() string {
+	return p.encodeValues().Encode()
+}
+
+// Tunnel types which routes can belong to, as used by TunnelRoute.TunnelType
+// and TunnelRoutesListParams.TunnelTypes.
+const (
+	TunnelRouteTypeCloudflared   = "cfd_tunnel"
+	TunnelRouteTypeWarpConnector = "warp_connector"
+)
+
+// TunnelRoutesFilter holds filters that the API does not support server side.
+// They are applied client side by ListTunnelRoutesFiltered.
+type TunnelRoutesFilter struct {
+	// CreatedAfter only includes routes created after this time.
+	CreatedAfter *time.Time
+	// CreatedBefore only includes routes created before this time.
+	CreatedBefore *time.Time
+	// Comment only includes routes whose comment matches, as determined by
+	// CommentMatch.
+	Comment string
+	// CommentMatch controls how Comment is compared. Defaults to
+	// TunnelRouteCommentExact.
+	CommentMatch TunnelRouteCommentMatch
+}
+
+// TunnelRouteCommentMatch is the way a comment filter is compared against the
+// comment of a route.
+type TunnelRouteCommentMatch string
+
+const (
+	// TunnelRouteCommentExact matches routes with exactly the given comment.
+	// This is the only comparison the API supports server side.
+	TunnelRouteCommentExact TunnelRouteCommentMatch = "exact"
+	// TunnelRouteCommentContains matches routes whose comment contains the
+	// given value.
+	TunnelRouteCommentContains TunnelRouteCommentMatch = "contains"
+	// TunnelRouteCommentPrefix matches routes whose comment starts with the
+	// given value.
+	TunnelRouteCommentPrefix TunnelRouteCommentMatch = "prefix"
+)
+
+// Match returns whether the route satisfies every filter that has been set.
+func (f TunnelRoutesFilter) Match(route TunnelRoute) bool {
+	if f.CreatedAfter != nil && (route.CreatedAt == nil || !route.CreatedAt.After(*f.CreatedAfter)) {
+		return false
+	}
+
+	if f.CreatedBefore != nil && (route.CreatedAt == nil || !route.CreatedAt.Before(*f.CreatedBefore)) {
+		return false
+	}
+
+	if f.Comment != "" {
+		switch f.CommentMatch {
+		case TunnelRouteCommentContains:
+			return strings.Contains(route.Comment, f.Comment)
+		case TunnelRouteCommentPrefix:
+			return strings.HasPrefix(route.Comment, f.Comment)
+		default:
+			return route.Comment == f.Comment
+		}
+	}
+
+	return true
+}
+
 type TunnelRoutesCreateParams struct {
 	Network          string `json:"-"`
 	TunnelID         string `json:"tunnel_id"`
@@ -46,11 +324,162 @@ type TunnelRoutesCreateParams struct {
 	VirtualNetworkID string `json:"virtual_network_id,omitempty"`
 }
 
+// NewTunnelRoutesCreateParams returns the params for creating a route for the
+// network on the tunnel, ensuring neither is empty and that the network is a
+// valid CIDR range. The account is identified by the *ResourceContainer passed
+// to CreateTunnelRoute instead.
+func NewTunnelRoutesCreateParams(tunnelID, network string) (TunnelRoutesCreateParams, error) {
+	if tunnelID == "" {
+		return TunnelRoutesCreateParams{}, ErrMissingTunnelID
+	}
+
+	if network == "" {
+		return TunnelRoutesCreateParams{}, ErrMissingNetwork
+	}
+
+	if err := validateTunnelRouteNetwork(network); err != nil {
+		return TunnelRoutesCreateParams{}, err
+	}
+
+	return TunnelRoutesCreateParams{TunnelID: tunnelID, Network: network}, nil
+}
+
 type TunnelRoutesUpdateParams struct {
-	Network          string `json:"network"`
-	TunnelID         string `json:"tunnel_id"`
+	Network string `json:"network"`
+	// TunnelID is the tunnel the route is assigned to. When empty, the
+	// existing tunnel assignment is left untouched.
+	TunnelID         string `json:"tunnel_id,omitempty"`
 	Comment          string `json:"comment,omitempty"`
 	VirtualNetworkID string `json:"virtual_network_id,omitempty"`
+	// IfMatch is sent as the If-Match header, making the update conditional
+	// on the route being unchanged since the ETag was obtained. Should the
+	// route have been modified, ErrTunnelRouteConflict is returned. When the
+	// API does not send ETags there is nothing to match and IfMatch should be
+	// left empty, in which case the update is unconditional.
+	IfMatch string `json:"-"`
+}
+
+// TunnelRouteRequest is a request which would be sent to the API, as built by
+// PlanCreateTunnelRoute and PlanUpdateTunnelRoute.
+type TunnelRouteRequest struct {
+	Method string
+	URL    string
+	Body   []byte
+}
+
+// TunnelRoutesReplaceParams holds the complete set of routes desired for a
+// single tunnel.
+type TunnelRoutesReplaceParams struct {
+	TunnelID string
+	// Routes are the desired routes. Their TunnelID is ignored in favour of
+	// the TunnelID above.
+	Routes []TunnelRoutesCreateParams
+
+	// MaxDeletes is the largest number of routes which may be deleted, and
+	// MaxDeletePercent the largest percentage of the current routes of the
+	// tunnel. Should either be exceeded ErrTooManyDeletions is returned
+	// before any changes are made, guarding against bad input wiping out the
+	// routes of a tunnel. Zero means no limit.
+	MaxDeletes       int
+	MaxDeletePercent float64
+	// Force makes the changes regardless of MaxDeletes and MaxDeletePercent.
+	Force bool
+}
+
+// TunnelRoutesReplaceResult summarises the changes made by ReplaceTunnelRoutes.
+type TunnelRoutesReplaceResult struct {
+	Created []TunnelRoute
+	Updated []TunnelRoute
+	Deleted []TunnelRoute
+}
+
+// TunnelRoutesBulkCreateParams holds the routes to create in a single bulk
+// operation.
+type TunnelRoutesBulkCreateParams struct {
+	Routes []TunnelRoutesCreateParams
+
+	// MaxInFlight is the maximum number of create requests that will be in
+	// flight at any given time. Defaults to 4 when unset.
+	MaxInFlight int
+}
+
+// TunnelRoutesDeleteByFilterParams holds the filter selecting which routes
+// DeleteTunnelRoutesByFilter deletes.
+type TunnelRoutesDeleteByFilterParams struct {
+	Filter TunnelRoutesListParams
+
+	// Confirm must be set for any routes to be deleted, guarding against
+	// accidentally deleting every route in the account with an empty filter.
+	Confirm bool
+
+	// MaxInFlight is the maximum number of delete requests that will be in
+	// flight at any given time. Defaults to 4 when unset.
+	MaxInFlight int
+}
+
+// TunnelRouteResult is the outcome of a single route operation performed as
+// part of a bulk request.
+type TunnelRouteResult struct {
+	Network string
+	Route   TunnelRoute
+	Err     error
+}
+
+// TunnelRouteImportResult is the outcome of importing a single line with
+// ImportTunnelRoutes.
+type TunnelRouteImportResult struct {
+	// Line is the 1-based line number the route was read from.
+	Line int
+	TunnelRouteResult
+}
+
+// ErrInvalidTunnelRouteImportLine is reported for lines passed to
+// ImportTunnelRoutes which are not of the form "network tunnel_id [comment]".
+var ErrInvalidTunnelRouteImportLine = errors.New(`invalid route, expected "network tunnel_id [comment]"`)
+
+// TunnelRouteBulkError is returned when one or more of the operations in a
+// bulk request fail. The individual failures are also available on the
+// corresponding TunnelRouteResult.
+type TunnelRouteBulkError struct {
+	Errors []error
+}
+
+func (e *TunnelRouteBulkError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+
+	return fmt.Sprintf("%d tunnel route operation(s) failed: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// Is reports whether any of the individual failures match target.
+func (e *TunnelRouteBulkError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first individual failure that matches target. It is needed
+// alongside Unwrap for Go versions before 1.20, whose errors.As does not
+// look at multiple wrapped errors.
+func (e *TunnelRouteBulkError) As(target interface{}) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Unwrap returns the individual failures.
+func (e *TunnelRouteBulkError) Unwrap() []error {
+	return e.Errors
 }
 
 type TunnelRoutesForIPParams struct {
@@ -58,6 +487,11 @@ type TunnelRoutesForIPParams struct {
 	VirtualNetworkID string `url:"virtual_network_id,omitempty"`
 }
 
+type TunnelRoutesRestoreParams struct {
+	Network          string
+	VirtualNetworkID string
+}
+
 type TunnelRoutesDeleteParams struct {
 	Network          string `url:"-"`
 	VirtualNetworkID string `url:"virtual_network_id,omitempty"`
@@ -66,7 +500,8 @@ type TunnelRoutesDeleteParams struct {
 // tunnelRouteListResponse is the API response for listing tunnel routes.
 type tunnelRouteListResponse struct {
 	Response
-	Result []TunnelRoute `json:"result"`
+	Result     []TunnelRoute `json:"result"`
+	ResultInfo `json:"result_info"`
 }
 
 type tunnelRouteResponse struct {
@@ -76,139 +511,2282 @@ type tunnelRouteResponse struct {
 
 // ListTunnelRoutes lists all defined routes for tunnels in the account.
 //
+// Only the page of results described by params is returned. The ResultInfo
+// describes the pagination state so callers can drive their own pagination.
+//
 // See: https://api.cloudflare.com/#tunnel-route-list-tunnel-routes
-func (api *API) ListTunnelRoutes(ctx context.Context, rc *ResourceContainer, params TunnelRoutesListParams) ([]TunnelRoute, error) {
+func (api *API) ListTunnelRoutes(ctx context.Context, rc *ResourceContainer, params TunnelRoutesListParams) ([]TunnelRoute, *ResultInfo, error) {
+	rc = api.withDefaultAccount(rc)
+
 	if rc.Identifier == "" {
-		return []TunnelRoute{}, ErrMissingAccountID
+		return []TunnelRoute{}, &ResultInfo{}, ErrMissingAccountID
 	}
 
-	uri := buildURI(fmt.Sprintf("/%s/%s/teamnet/routes", AccountRouteRoot, rc.Identifier), params)
-	res, err := api.makeRequestContext(ctx, http.MethodGet, uri, nil)
+	ctx, cancel := params.withTimeout(ctx)
+	defer cancel()
+
+	routes, resultInfo, err := api.listTunnelRoutesPage(ctx, rc, params)
 	if err != nil {
-		return []TunnelRoute{}, err
+		return []TunnelRoute{}, &ResultInfo{}, err
 	}
 
-	var resp tunnelRouteListResponse
-	err = json.Unmarshal(res, &resp)
-	if err != nil {
-		return []TunnelRoute{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+	return routes, &resultInfo, nil
+}
+
+// CountTunnelRoutes returns the total number of routes matching params by
+// requesting a single route and reading the total from the result info. Any
+// pagination options in params are ignored. With more than one of TunnelIDs
+// the routes of each tunnel are counted in turn and summed.
+func (api *API) CountTunnelRoutes(ctx context.Context, rc *ResourceContainer, params TunnelRoutesListParams) (int, error) {
+	rc = api.withDefaultAccount(rc)
+
+	params.PaginationOptions = PaginationOptions{Page: 1, PerPage: 1}
+	params.Cursor = ""
+
+	if len(params.TunnelIDs) <= 1 {
+		_, resultInfo, err := api.ListTunnelRoutes(ctx, rc, params)
+		if err != nil {
+			return 0, err
+		}
+
+		return resultInfo.Total, nil
+	}
+
+	total := 0
+	counted := make(map[string]struct{}, len(params.TunnelIDs))
+	for _, tunnelID := range params.TunnelIDs {
+		if _, ok := counted[tunnelID]; ok {
+			continue
+		}
+		counted[tunnelID] = struct{}{}
+
+		tunnelParams := params
+		tunnelParams.TunnelID = tunnelID
+		tunnelParams.TunnelIDs = nil
+
+		_, resultInfo, err := api.ListTunnelRoutes(ctx, rc, tunnelParams)
+		if err != nil {
+			return 0, err
+		}
+		total += resultInfo.Total
 	}
 
-	return resp.Result, nil
+	return total, nil
 }
 
-// GetTunnelRouteForIP finds the Tunnel Route that encompasses the given IP.
-//
-// See: https://api.cloudflare.com/#tunnel-route-get-tunnel-route-by-ip
-func (api *API) GetTunnelRouteForIP(ctx context.Context, rc *ResourceContainer, params TunnelRoutesForIPParams) (TunnelRoute, error) {
+// PingTeamnet checks that the account is reachable and that the token is
+// authorised to use the teamnet routes API, by listing a single route. It is
+// intended as a preflight check before making a batch of changes. The
+// returned error can be checked using IsAuthFailure, IsNotFound or errors.Is
+// with ErrTunnelRoutePermission.
+func (api *API) PingTeamnet(ctx context.Context, rc *ResourceContainer) error {
+	rc = api.withDefaultAccount(rc)
+
 	if rc.Identifier == "" {
-		return TunnelRoute{}, ErrMissingAccountID
+		return ErrMissingAccountID
 	}
 
-	if params.Network == "" {
-		return TunnelRoute{}, ErrMissingNetwork
+	_, _, err := api.listTunnelRoutesPage(ctx, rc, TunnelRoutesListParams{PaginationOptions: PaginationOptions{Page: 1, PerPage: 1}})
+	if err != nil {
+		return fmt.Errorf("teamnet is not available for account %s: %w", rc.Identifier, err)
 	}
 
-	if strings.Contains(params.Network, "/") {
-		return TunnelRoute{}, ErrInvalidNetworkValue
+	return nil
+}
+
+// ListTunnelRoutesAll lists all defined routes for tunnels in the account,
+// walking every page of results until they are exhausted. The PerPage value
+// of params is respected however Page is managed internally.
+//
+// The returned ResultInfo is the one from the last page fetched and can be
+// used to inspect the total number of routes. Should the context be cancelled
+// part way through, the routes fetched so far are returned along with the
+// error.
+func (api *API) ListTunnelRoutesAll(ctx context.Context, rc *ResourceContainer, params TunnelRoutesListParams) ([]TunnelRoute, *ResultInfo, error) {
+	rc = api.withDefaultAccount(rc)
+
+	if rc.Identifier == "" {
+		return []TunnelRoute{}, &ResultInfo{}, ErrMissingAccountID
 	}
 
-	uri := buildURI(fmt.Sprintf("/%s/%s/teamnet/routes/ip/%s", AccountRouteRoot, rc.Identifier, params.Network), params)
+	ctx, cancel := params.withTimeout(ctx)
+	defer cancel()
 
-	responseBody, err := api.makeRequestContext(ctx, http.MethodGet, uri, nil)
+	var routes []TunnelRoute
+	resultInfo, err := api.walkTunnelRoutes(ctx, rc, params, func(page []TunnelRoute, info ResultInfo) error {
+		if routes == nil && info.Total > 0 {
+			routes = make([]TunnelRoute, 0, info.Total)
+		}
+
+		routes = append(routes, page...)
+		return nil
+	})
+
+	return routes, &resultInfo, err
+}
+
+// ListTunnelRoutesMap lists all defined routes for tunnels in the account
+// matching params, keyed by network and virtual network. Every page of results
+// is fetched, see ListTunnelRoutesAll. Should two routes share a key, which can
+// happen when deleted routes are included, ErrDuplicateTunnelRoute is
+// returned rather than one silently replacing the other.
+func (api *API) ListTunnelRoutesMap(ctx context.Context, rc *ResourceContainer, params TunnelRoutesListParams) (map[TunnelRouteKey]TunnelRoute, error) {
+	routes, _, err := api.ListTunnelRoutesAll(ctx, rc, params)
 	if err != nil {
-		return TunnelRoute{}, err
+		return nil, err
 	}
 
-	var routeResponse tunnelRouteResponse
-	err = json.Unmarshal(responseBody, &routeResponse)
-	if err != nil {
-		return TunnelRoute{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+	routesByKey := make(map[TunnelRouteKey]TunnelRoute, len(routes))
+	for _, route := range routes {
+		key := route.Key()
+		if _, ok := routesByKey[key]; ok {
+			return nil, fmt.Errorf("%w: %q in virtual network %q", ErrDuplicateTunnelRoute, key.Network, key.VirtualNetworkID)
+		}
+		routesByKey[key] = route
 	}
 
-	return routeResponse.Result, nil
+	return routesByKey, nil
 }
 
-// CreateTunnelRoute add a new route to the account routing table for the given
-// tunnel.
-//
-// See: https://api.cloudflare.com/#tunnel-route-create-route
-func (api *API) CreateTunnelRoute(ctx context.Context, rc *ResourceContainer, params TunnelRoutesCreateParams) (TunnelRoute, error) {
+// ListTunnelRoutesFilteredParams holds the server side filters for listing
+// routes along with the client side Filter.
+type ListTunnelRoutesFilteredParams struct {
+	TunnelRoutesListParams
+	Filter TunnelRoutesFilter
+}
+
+// ListTunnelRoutesFiltered lists all defined routes for tunnels in the account
+// which match both the server side filters in params and the client side
+// filters in params.Filter. Every page of results is fetched, see
+// ListTunnelRoutesAll.
+func (api *API) ListTunnelRoutesFiltered(ctx context.Context, rc *ResourceContainer, params ListTunnelRoutesFilteredParams) ([]TunnelRoute, error) {
+	rc = api.withDefaultAccount(rc)
+
 	if rc.Identifier == "" {
-		return TunnelRoute{}, ErrMissingAccountID
+		return []TunnelRoute{}, ErrMissingAccountID
 	}
 
-	if params.Network == "" {
-		return TunnelRoute{}, ErrMissingNetwork
+	// Exact comment matches can be filtered by the API, saving the transfer
+	// of routes that would be discarded anyway.
+	if params.Filter.Comment != "" && params.Comment == "" &&
+		(params.Filter.CommentMatch == "" || params.Filter.CommentMatch == TunnelRouteCommentExact) {
+		params.Comment = params.Filter.Comment
 	}
 
-	uri := fmt.Sprintf("/%s/%s/teamnet/routes/network/%s", AccountRouteRoot, rc.Identifier, url.PathEscape(params.Network))
+	ctx, cancel := params.withTimeout(ctx)
+	defer cancel()
 
-	responseBody, err := api.makeRequestContext(ctx, http.MethodPost, uri, params)
+	routes := []TunnelRoute{}
+	_, err := api.walkTunnelRoutes(ctx, rc, params.TunnelRoutesListParams, func(page []TunnelRoute, _ ResultInfo) error {
+		for _, route := range page {
+			if params.Filter.Match(route) {
+				routes = append(routes, route)
+			}
+		}
+
+		return nil
+	})
 	if err != nil {
-		return TunnelRoute{}, err
+		return []TunnelRoute{}, err
 	}
 
-	var routeResponse tunnelRouteResponse
-	err = json.Unmarshal(responseBody, &routeResponse)
+	return routes, nil
+}
+
+// TunnelRouteNetworkFamily is the IP version of the network of a route.
+type TunnelRouteNetworkFamily string
+
+const (
+	TunnelRouteNetworkIPv4 TunnelRouteNetworkFamily = "v4"
+	TunnelRouteNetworkIPv6 TunnelRouteNetworkFamily = "v6"
+)
+
+// ErrInvalidNetworkFamily is returned by ListTunnelRoutesByFamily for an
+// unknown family.
+var ErrInvalidNetworkFamily = errors.New("invalid network family, must be v4 or v6")
+
+// ListTunnelRoutesByFamilyParams holds the filters for listing routes along
+// with the IP version of their networks.
+type ListTunnelRoutesByFamilyParams struct {
+	TunnelRoutesListParams
+	// Family is the IP version routes must have, either
+	// TunnelRouteNetworkIPv4 or TunnelRouteNetworkIPv6.
+	Family TunnelRouteNetworkFamily
+}
+
+// ListTunnelRoutesByFamily lists all defined routes for tunnels in the account
+// matching params whose network is of the given IP version. The API cannot
+// filter by IP version so every page is fetched, see ListTunnelRoutesAll, and
+// the networks are checked client side. IPv4-mapped IPv6 networks, such as
+// ::ffff:10.0.0.0/104, are considered to be IPv4.
+//
+// Routes whose network cannot be parsed are not silently dropped; the routes
+// which did match are returned along with an error wrapping
+// ErrInvalidNetworkCIDR that lists the invalid networks.
+func (api *API) ListTunnelRoutesByFamily(ctx context.Context, rc *ResourceContainer, params ListTunnelRoutesByFamilyParams) ([]TunnelRoute, error) {
+	rc = api.withDefaultAccount(rc)
+
+	if params.Family != TunnelRouteNetworkIPv4 && params.Family != TunnelRouteNetworkIPv6 {
+		return []TunnelRoute{}, fmt.Errorf("%w: %q", ErrInvalidNetworkFamily, params.Family)
+	}
+
+	routes, _, err := api.ListTunnelRoutesAll(ctx, rc, params.TunnelRoutesListParams)
 	if err != nil {
-		return TunnelRoute{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return []TunnelRoute{}, err
 	}
 
-	return routeResponse.Result, nil
+	matched := []TunnelRoute{}
+	var invalid []string
+	for _, route := range routes {
+		_, network, err := net.ParseCIDR(route.Network)
+		if err != nil {
+			invalid = append(invalid, strconv.Quote(route.Network))
+			continue
+		}
+
+		isIPv4 := network.IP.To4() != nil
+		if isIPv4 == (params.Family == TunnelRouteNetworkIPv4) {
+			matched = append(matched, route)
+		}
+	}
+
+	if len(invalid) > 0 {
+		return matched, fmt.Errorf("%w: %s", ErrInvalidNetworkCIDR, strings.Join(invalid, ", "))
+	}
+
+	return matched, nil
 }
 
-// DeleteTunnelRoute delete an existing route from the account routing table.
-//
-// See: https://api.cloudflare.com/#tunnel-route-delete-route
-func (api *API) DeleteTunnelRoute(ctx context.Context, rc *ResourceContainer, params TunnelRoutesDeleteParams) error {
+// TunnelRoutesExportFormat is the format ExportTunnelRoutes writes routes in.
+type TunnelRoutesExportFormat string
+
+const (
+	// TunnelRoutesExportCSV writes a header row followed by a row per route
+	// with the columns network, tunnel_id, tunnel_name, comment and
+	// created_at.
+	TunnelRoutesExportCSV TunnelRoutesExportFormat = "csv"
+	// TunnelRoutesExportJSONLines writes each route as a JSON object on its
+	// own line.
+	TunnelRoutesExportJSONLines TunnelRoutesExportFormat = "jsonl"
+)
+
+// ErrInvalidExportFormat is returned by ExportTunnelRoutes for an unknown
+// format.
+var ErrInvalidExportFormat = errors.New("invalid tunnel routes export format")
+
+// ErrMissingExportWriter is returned by ExportTunnelRoutes when there is no
+// Writer to export the routes to.
+var ErrMissingExportWriter = errors.New("missing writer to export tunnel routes to")
+
+// ExportTunnelRoutesParams holds the filters for the routes to export along
+// with where and how they are written.
+type ExportTunnelRoutesParams struct {
+	TunnelRoutesListParams
+	// Writer is where the routes are written to.
+	Writer io.Writer
+	// Format is the format the routes are written in.
+	Format TunnelRoutesExportFormat
+}
+
+// ExportTunnelRoutes writes every route matching params to the Writer in the
+// given Format. Routes are written a page at a time as they are fetched rather
+// than being held in memory.
+func (api *API) ExportTunnelRoutes(ctx context.Context, rc *ResourceContainer, params ExportTunnelRoutesParams) error {
+	rc = api.withDefaultAccount(rc)
+
 	if rc.Identifier == "" {
 		return ErrMissingAccountID
 	}
 
-	if params.Network == "" {
-		return ErrMissingNetwork
+	if params.Writer == nil {
+		return ErrMissingExportWriter
 	}
 
-	// Cannot fully utilize buildURI here because it tries to escape "%" sign
-	// from the already escaped "/" sign from Network field.
-	uri := fmt.Sprintf("/%s/%s/teamnet/routes/network/%s%s", AccountRouteRoot, rc.Identifier, url.PathEscape(params.Network), buildURI("", params))
+	var writePage func(page []TunnelRoute) error
+	switch params.Format {
+	case TunnelRoutesExportCSV:
+		cw := csv.NewWriter(params.Writer)
+		if err := cw.Write([]string{"network", "tunnel_id", "tunnel_name", "comment", "created_at"}); err != nil {
+			return err
+		}
 
-	responseBody, err := api.makeRequestContext(ctx, http.MethodDelete, uri, nil)
-	if err != nil {
-		return err
+		writePage = func(page []TunnelRoute) error {
+			for _, route := range page {
+				var createdAt string
+				if formatted := formatTunnelRouteTime(route.CreatedAt); formatted != nil {
+					createdAt = *formatted
+				}
+
+				if err := cw.Write([]string{route.Network, route.TunnelID, route.TunnelName, route.Comment, createdAt}); err != nil {
+					return err
+				}
+			}
+
+			cw.Flush()
+			return cw.Error()
+		}
+	case TunnelRoutesExportJSONLines:
+		enc := json.NewEncoder(params.Writer)
+		writePage = func(page []TunnelRoute) error {
+			for _, route := range page {
+				if err := enc.Encode(utcTunnelRoute(route)); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidExportFormat, params.Format)
 	}
 
-	var routeResponse tunnelRouteResponse
-	err = json.Unmarshal(responseBody, &routeResponse)
+	_, err := api.walkTunnelRoutes(ctx, rc, params.TunnelRoutesListParams, func(page []TunnelRoute, _ ResultInfo) error {
+		return writePage(page)
+	})
+
+	return err
+}
+
+// TunnelRoutesSortBy is the field ListTunnelRoutesSorted orders routes by.
+type TunnelRoutesSortBy string
+
+const (
+	// TunnelRoutesSortByNetwork orders routes by network, see
+	// ListTunnelRoutesSorted.
+	TunnelRoutesSortByNetwork TunnelRoutesSortBy = "network"
+	// TunnelRoutesSortByCreatedAt orders routes by when they were created.
+	// Routes without a creation time are ordered last in either direction.
+	TunnelRoutesSortByCreatedAt TunnelRoutesSortBy = "created_at"
+)
+
+// ListTunnelRoutesSortedParams holds the filters for listing routes along with
+// the order they are returned in.
+type ListTunnelRoutesSortedParams struct {
+	TunnelRoutesListParams
+	// SortBy is the field routes are ordered by, defaulting to
+	// TunnelRoutesSortByNetwork.
+	SortBy TunnelRoutesSortBy
+	// Direction defaults to ascending.
+	Direction OrderDirection
+}
+
+// ListTunnelRoutesSorted lists all defined routes for tunnels in the account
+// matching params, ordered by SortBy in the given Direction. The API does not
+// support ordering routes so every page is fetched, see ListTunnelRoutesAll,
+// and then sorted. The sort is stable, with ties broken by network.
+//
+// Networks are ordered using CompareNetworks.
+func (api *API) ListTunnelRoutesSorted(ctx context.Context, rc *ResourceContainer, params ListTunnelRoutesSortedParams) ([]TunnelRoute, error) {
+	routes, _, err := api.ListTunnelRoutesAll(ctx, rc, params.TunnelRoutesListParams)
 	if err != nil {
-		return fmt.Errorf("%s: %w", errUnmarshalError, err)
+		return []TunnelRoute{}, err
 	}
 
-	return nil
+	order := 1
+	if params.Direction == OrderDirectionDesc {
+		order = -1
+	}
+
+	compare := func(a, b TunnelRoute) int {
+		if params.SortBy == TunnelRoutesSortByCreatedAt {
+			// routes without a creation time stay last whatever the direction
+			switch {
+			case a.CreatedAt == nil && b.CreatedAt != nil:
+				return 1
+			case a.CreatedAt != nil && b.CreatedAt == nil:
+				return -1
+			case a.CreatedAt != nil && b.CreatedAt != nil && !a.CreatedAt.Equal(*b.CreatedAt):
+				if a.CreatedAt.Before(*b.CreatedAt) {
+					return -order
+				}
+				return order
+			}
+		}
+
+		return order * CompareNetworks(a.Network, b.Network)
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		return compare(routes[i], routes[j]) < 0
+	})
+
+	return routes, nil
 }
 
-// UpdateTunnelRoute updates an existing route in the account routing table for
-// the given tunnel.
+// CompareNetworks compares two networks in CIDR notation numerically,
+// returning a negative number when a orders before b, a positive number when
+// it orders after and zero when they are equal.
 //
-// See: https://api.cloudflare.com/#tunnel-route-update-route
-func (api *API) UpdateTunnelRoute(ctx context.Context, rc *ResourceContainer, params TunnelRoutesUpdateParams) (TunnelRoute, error) {
-	if rc.Identifier == "" {
-		return TunnelRoute{}, ErrMissingAccountID
+// Networks are ordered with IPv4 before IPv6, then by address and finally by
+// prefix length, so that a network comes before the smaller networks it
+// contains. IPv4-mapped IPv6 networks are treated as IPv4. Networks which
+// cannot be parsed are ordered last, lexically.
+func CompareNetworks(a, b string) int {
+	_, aNet, aErr := net.ParseCIDR(a)
+	_, bNet, bErr := net.ParseCIDR(b)
+
+	switch {
+	case aErr != nil && bErr != nil:
+		return strings.Compare(a, b)
+	case aErr != nil:
+		return 1
+	case bErr != nil:
+		return -1
 	}
 
-	uri := fmt.Sprintf("/%s/%s/teamnet/routes/network/%s", AccountRouteRoot, rc.Identifier, url.PathEscape(params.Network))
+	aIP, aOnes, _ := normalizeIPNet(aNet)
+	bIP, bOnes, _ := normalizeIPNet(bNet)
 
-	responseBody, err := api.makeRequestContext(ctx, http.MethodPatch, uri, params)
-	if err != nil {
-		return TunnelRoute{}, err
+	if len(aIP) != len(bIP) {
+		return len(aIP) - len(bIP)
 	}
 
-	var routeResponse tunnelRouteResponse
-	err = json.Unmarshal(responseBody, &routeResponse)
-	if err != nil {
-		return TunnelRoute{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+	if c := bytes.Compare(aIP, bIP); c != 0 {
+		return c
 	}
 
-	return routeResponse.Result, nil
+	return aOnes - bOnes
+}
+
+// ByNetwork implements sort.Interface, ordering routes by network using
+// CompareNetworks. Use sort.Stable to keep routes for the same network in
+// different virtual networks in their original order.
+type ByNetwork []TunnelRoute
+
+func (r ByNetwork) Len() int           { return len(r) }
+func (r ByNetwork) Less(i, j int) bool { return CompareNetworks(r[i].Network, r[j].Network) < 0 }
+func (r ByNetwork) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+
+// StreamTunnelRoutes lists all defined routes for tunnels in the account,
+// sending each route on the returned channel as pages of results arrive.
+// This allows large numbers of routes to be processed without holding them
+// all in memory.
+//
+// Both channels are closed once every page has been fetched, an error occurs
+// or ctx is cancelled. At most one error is sent on the error channel.
+func (api *API) StreamTunnelRoutes(ctx context.Context, rc *ResourceContainer, params TunnelRoutesListParams) (<-chan TunnelRoute, <-chan error) {
+	rc = api.withDefaultAccount(rc)
+
+	routes := make(chan TunnelRoute)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(routes)
+		defer close(errc)
+
+		if rc.Identifier == "" {
+			errc <- ErrMissingAccountID
+			return
+		}
+
+		ctx, cancel := params.withTimeout(ctx)
+		defer cancel()
+
+		_, err := api.walkTunnelRoutes(ctx, rc, params, func(page []TunnelRoute, _ ResultInfo) error {
+			for _, route := range page {
+				select {
+				case routes <- route:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			errc <- err
+		}
+	}()
+
+	return routes, errc
+}
+
+// ErrMissingForEachFunc is returned by ForEachTunnelRoute when there is no Fn
+// to call for each route.
+var ErrMissingForEachFunc = errors.New("missing function to call for each tunnel route")
+
+// ForEachTunnelRouteParams holds the filters for the routes to iterate over
+// along with the function called for each of them.
+type ForEachTunnelRouteParams struct {
+	TunnelRoutesListParams
+	// Fn is called with each route in turn.
+	Fn func(TunnelRoute) error
+}
+
+// ForEachTunnelRoute calls Fn for each route for tunnels in the account
+// matching params, fetching one page at a time so that only a single page of
+// routes is held in memory. Iteration stops at the first error returned by Fn,
+// which is returned as is, or once ctx is cancelled.
+func (api *API) ForEachTunnelRoute(ctx context.Context, rc *ResourceContainer, params ForEachTunnelRouteParams) error {
+	rc = api.withDefaultAccount(rc)
+
+	if rc.Identifier == "" {
+		return ErrMissingAccountID
+	}
+
+	if params.Fn == nil {
+		return ErrMissingForEachFunc
+	}
+
+	ctx, cancel := params.withTimeout(ctx)
+	defer cancel()
+
+	_, err := api.walkTunnelRoutes(ctx, rc, params.TunnelRoutesListParams, func(page []TunnelRoute, _ ResultInfo) error {
+		for _, route := range page {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			if err := params.Fn(route); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+// TunnelRouteChangeType describes how a route changed between two polls of
+// WatchTunnelRoutes.
+type TunnelRouteChangeType string
+
+const (
+	TunnelRouteAdded   TunnelRouteChangeType = "added"
+	TunnelRouteUpdated TunnelRouteChangeType = "updated"
+	TunnelRouteRemoved TunnelRouteChangeType = "removed"
+)
+
+// TunnelRouteChange is a change to a route observed by WatchTunnelRoutes.
+// Route is the route as last seen, which for a removed route is the route
+// before it was removed.
+type TunnelRouteChange struct {
+	Type  TunnelRouteChangeType
+	Route TunnelRoute
+}
+
+// WatchTunnelRoutesParams holds the filters for the routes to watch along with
+// how often they are polled.
+type WatchTunnelRoutesParams struct {
+	TunnelRoutesListParams
+	// Interval is the time between polls, which must be positive.
+	Interval time.Duration
+}
+
+// WatchTunnelRoutes polls the routes matching params every Interval and sends
+// the changes since the previous poll on the returned channel. The first poll
+// only records the routes already present. Routes are matched as in
+// DiffTunnelRoutes, so any number of edits to a route between two polls are
+// reported as a single change.
+//
+// A failed poll sends its error on the error channel, without blocking if a
+// previous error has not been received yet, and polling continues. Both
+// channels are closed once ctx is cancelled, or straight away after sending
+// ErrMissingAccountID or ErrInvalidWatchInterval.
+func (api *API) WatchTunnelRoutes(ctx context.Context, rc *ResourceContainer, params WatchTunnelRoutesParams) (<-chan TunnelRouteChange, <-chan error) {
+	rc = api.withDefaultAccount(rc)
+
+	changes := make(chan TunnelRouteChange)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(changes)
+		defer close(errc)
+
+		if rc.Identifier == "" {
+			errc <- ErrMissingAccountID
+			return
+		}
+
+		if params.Interval <= 0 {
+			errc <- ErrInvalidWatchInterval
+			return
+		}
+
+		ticker := time.NewTicker(params.Interval)
+		defer ticker.Stop()
+
+		var previous []TunnelRoute
+		polled := false
+		for {
+			current, _, err := api.ListTunnelRoutesAll(ctx, rc, params.TunnelRoutesListParams)
+			switch {
+			case ctx.Err() != nil:
+				return
+			case err != nil:
+				select {
+				case errc <- err:
+				default:
+				}
+			case !polled:
+				previous, polled = current, true
+			default:
+				for _, change := range diffTunnelRouteSnapshots(previous, current) {
+					select {
+					case changes <- change:
+					case <-ctx.Done():
+						return
+					}
+				}
+				previous = current
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return changes, errc
+}
+
+// diffTunnelRouteSnapshots returns the changes between two listings of routes.
+func diffTunnelRouteSnapshots(previous, current []TunnelRoute) []TunnelRouteChange {
+	added, updated, removed := DiffTunnelRoutes(current, previous)
+
+	latest := make(map[TunnelRouteKey]TunnelRoute, len(current))
+	for _, route := range current {
+		latest[route.Key()] = route
+	}
+
+	changes := make([]TunnelRouteChange, 0, len(added)+len(updated)+len(removed))
+	for _, route := range added {
+		changes = append(changes, TunnelRouteChange{Type: TunnelRouteAdded, Route: route})
+	}
+	for _, route := range updated {
+		changes = append(changes, TunnelRouteChange{Type: TunnelRouteUpdated, Route: latest[route.Key()]})
+	}
+	for _, route := range removed {
+		changes = append(changes, TunnelRouteChange{Type: TunnelRouteRemoved, Route: route})
+	}
+
+	return changes
+}
+
+// walkTunnelRoutes fetches every page of tunnel routes starting from the
+// first, calling fn with each page until they are exhausted, fn returns an
+// error or ctx is cancelled. The ResultInfo of the last page fetched is
+// returned. ErrPaginationLimitExceeded is returned rather than fetching more
+// than MaxPages pages.
+//
+// When params has more than one of TunnelIDs, the pages of each tunnel are
+// fetched in turn with routes already seen, by ID, removed from later pages.
+// The returned ResultInfo is then that of the last tunnel with Count and Total
+// summed across every tunnel.
+func (api *API) walkTunnelRoutes(ctx context.Context, rc *ResourceContainer, params TunnelRoutesListParams, fn func(page []TunnelRoute, info ResultInfo) error) (ResultInfo, error) {
+	if params.MaxPages < 0 {
+		return ResultInfo{}, fmt.Errorf("%w: %d", ErrInvalidMaxPages, params.MaxPages)
+	}
+
+	maxPages := params.MaxPages
+	if maxPages == 0 {
+		maxPages = listTunnelRoutesDefaultMaxPages
+	}
+	pages := 0
+
+	if len(params.TunnelIDs) <= 1 {
+		return api.walkTunnelRoutePages(ctx, rc, params, &pages, maxPages, fn)
+	}
+
+	var resultInfo ResultInfo
+	count, total := 0, 0
+	walked := make(map[string]struct{}, len(params.TunnelIDs))
+	seen := make(map[string]struct{})
+	for _, tunnelID := range params.TunnelIDs {
+		if _, ok := walked[tunnelID]; ok {
+			continue
+		}
+		walked[tunnelID] = struct{}{}
+
+		tunnelParams := params
+		tunnelParams.TunnelID = tunnelID
+		tunnelParams.TunnelIDs = nil
+
+		var err error
+		resultInfo, err = api.walkTunnelRoutePages(ctx, rc, tunnelParams, &pages, maxPages, func(page []TunnelRoute, info ResultInfo) error {
+			unseen := make([]TunnelRoute, 0, len(page))
+			for _, route := range page {
+				if route.ID != "" {
+					if _, ok := seen[route.ID]; ok {
+						continue
+					}
+					seen[route.ID] = struct{}{}
+				}
+				unseen = append(unseen, route)
+			}
+
+			return fn(unseen, info)
+		})
+		count += resultInfo.Count
+		total += resultInfo.Total
+		resultInfo.Count, resultInfo.Total = count, total
+		if err != nil {
+			return resultInfo, err
+		}
+	}
+
+	return resultInfo, nil
+}
+
+// walkTunnelRoutePages fetches the pages of tunnel routes for walkTunnelRoutes,
+// counting them in pages.
+func (api *API) walkTunnelRoutePages(ctx context.Context, rc *ResourceContainer, params TunnelRoutesListParams, pages *int, maxPages int, fn func(page []TunnelRoute, info ResultInfo) error) (ResultInfo, error) {
+	var resultInfo ResultInfo
+
+	params.Page = 1
+	if params.Cursor != "" {
+		params.Page = 0
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return resultInfo, err
+		}
+
+		if *pages >= maxPages {
+			return resultInfo, fmt.Errorf("%w: fetched %d pages", ErrPaginationLimitExceeded, *pages)
+		}
+		*pages++
+
+		page, info, err := api.listTunnelRoutesPage(ctx, rc, params)
+		if err != nil {
+			return resultInfo, err
+		}
+
+		resultInfo = info
+		if err := fn(page, info); err != nil {
+			return resultInfo, err
+		}
+
+		if len(page) == 0 {
+			return resultInfo, nil
+		}
+
+		// Prefer a cursor when the server provides one, falling back to
+		// page numbers otherwise. Once following cursors the absence of one
+		// marks the end of the results.
+		if cursor := nextTunnelRoutesCursor(info); cursor != "" {
+			if cursor == params.Cursor {
+				return resultInfo, nil
+			}
+
+			params.Cursor = cursor
+			params.Page = 0
+			continue
+		}
+
+		if params.Cursor != "" || !info.HasMorePages() {
+			return resultInfo, nil
+		}
+
+		params.Page++
+	}
+}
+
+// nextTunnelRoutesCursor returns the cursor for the next page of results, if
+// any, from either of the cursor formats used in result info.
+func nextTunnelRoutesCursor(info ResultInfo) string {
+	if info.Cursors.After != "" {
+		return info.Cursors.After
+	}
+
+	return info.Cursor
+}
+
+// listTunnelRoutesPage fetches a single page of tunnel routes.
+func (api *API) listTunnelRoutesPage(ctx context.Context, rc *ResourceContainer, params TunnelRoutesListParams) (_ []TunnelRoute, _ ResultInfo, err error) {
+	ctx, span := api.startSpan(ctx, "ListTunnelRoutes", rc, params.TunnelID)
+	defer func() { endSpan(span, err) }()
+
+	if params.PerPage == 0 {
+		params.PerPage = listTunnelRoutesDefaultPageSize
+	}
+
+	if params.PerPage < 1 || params.PerPage > listTunnelRoutesMaxPageSize {
+		return []TunnelRoute{}, ResultInfo{}, ErrInvalidPerPage
+	}
+
+	if len(params.TunnelIDs) > 1 {
+		return []TunnelRoute{}, ResultInfo{}, ErrMultipleTunnelIDs
+	}
+
+	uri := api.buildTeamnetURL(rc.Identifier, "routes")
+	if query := params.encode(); query != "" {
+		uri += "?" + query
+	}
+
+	res, err := api.makeRequestContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return []TunnelRoute{}, ResultInfo{}, tunnelRoutePermissionError(err, "")
+	}
+
+	var resp tunnelRouteListResponse
+	err = api.unmarshalResponse(res, &resp)
+	if err != nil {
+		return []TunnelRoute{}, ResultInfo{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+	}
+
+	if err := tunnelRouteResponseError(resp.Response, http.StatusOK); err != nil {
+		return []TunnelRoute{}, ResultInfo{}, err
+	}
+
+	return resp.Result, resp.ResultInfo, nil
+}
+
+// GetTunnelRoute returns a single route using its ID. Unlike the network, the
+// ID of a route is stable and unique across virtual networks.
+//
+// See: https://developers.cloudflare.com/api/operations/tunnel-route-get-tunnel-route
+func (api *API) GetTunnelRoute(ctx context.Context, rc *ResourceContainer, routeID string) (_ TunnelRoute, err error) {
+	rc = api.withDefaultAccount(rc)
+
+	ctx, span := api.startSpan(ctx, "GetTunnelRoute", rc, "")
+	defer func() { endSpan(span, err) }()
+
+	if rc.Identifier == "" {
+		return TunnelRoute{}, ErrMissingAccountID
+	}
+
+	if routeID == "" {
+		return TunnelRoute{}, ErrMissingTunnelRouteID
+	}
+
+	uri := api.buildTeamnetURL(rc.Identifier, "routes", routeID)
+
+	res, err := api.makeRequestContextWithHeadersComplete(ctx, http.MethodGet, uri, nil, nil)
+	if err != nil {
+		return TunnelRoute{}, tunnelRoutePermissionError(err, "")
+	}
+
+	var routeResponse tunnelRouteResponse
+	err = api.unmarshalResponse(res.Body, &routeResponse)
+	if err != nil {
+		return TunnelRoute{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+	}
+
+	if err := tunnelRouteResponseError(routeResponse.Response, res.StatusCode); err != nil {
+		return TunnelRoute{}, err
+	}
+
+	routeResponse.Result.ETag = res.Headers.Get("ETag")
+	return routeResponse.Result, nil
+}
+
+// ListTunnelRoutesContainingParams holds the filters for listing the routes
+// containing an IP address or CIDR range.
+type ListTunnelRoutesContainingParams struct {
+	TunnelRoutesListParams
+	// IP is the IP address, or CIDR range, routes must contain.
+	IP string
+}
+
+// ListTunnelRoutesContaining lists all routes matching params whose network
+// contains the IP address, or the whole of the CIDR range, by setting
+// NetworkSuperset. Unlike GetTunnelRouteForIP, which returns only the most
+// specific route, every containing route is returned.
+func (api *API) ListTunnelRoutesContaining(ctx context.Context, rc *ResourceContainer, params ListTunnelRoutesContainingParams) ([]TunnelRoute, error) {
+	rc = api.withDefaultAccount(rc)
+
+	network := params.IP
+	if !strings.Contains(params.IP, "/") {
+		var ok bool
+		if network, ok = hostNetwork(params.IP); !ok {
+			return []TunnelRoute{}, fmt.Errorf("%w: %q", ErrInvalidNetworkValue, params.IP)
+		}
+	}
+
+	if err := validateTunnelRouteNetwork(network); err != nil {
+		return []TunnelRoute{}, err
+	}
+
+	params.NetworkSuperset = network
+	routes, _, err := api.ListTunnelRoutesAll(ctx, rc, params.TunnelRoutesListParams)
+	return routes, err
+}
+
+// ListTunnelRoutesContainedByParams holds the filters for listing the routes
+// within a CIDR range.
+type ListTunnelRoutesContainedByParams struct {
+	TunnelRoutesListParams
+	// Network is the CIDR range routes must lie within.
+	Network string
+}
+
+// ListTunnelRoutesContainedBy lists all routes matching params whose network
+// lies within the CIDR range, including a route for exactly that range, by
+// setting NetworkSubset.
+func (api *API) ListTunnelRoutesContainedBy(ctx context.Context, rc *ResourceContainer, params ListTunnelRoutesContainedByParams) ([]TunnelRoute, error) {
+	rc = api.withDefaultAccount(rc)
+
+	if err := validateTunnelRouteNetwork(params.Network); err != nil {
+		return []TunnelRoute{}, err
+	}
+
+	params.NetworkSubset = params.Network
+	routes, _, err := api.ListTunnelRoutesAll(ctx, rc, params.TunnelRoutesListParams)
+	return routes, err
+}
+
+// GetTunnelRouteByNetworkParams identifies the route to get by its network.
+type GetTunnelRouteByNetworkParams struct {
+	Network          string
+	VirtualNetworkID string
+}
+
+// GetTunnelRouteByNetwork returns the route for exactly the network, rather
+// than any network containing it as GetTunnelRouteForIP does. The API has no
+// endpoint for this so routes are listed using the network as both the subset
+// and superset, and then matched client side.
+//
+// When VirtualNetworkID is empty the route may be in any virtual network,
+// with ErrDuplicateTunnelRoute returned if there is more than one.
+// ErrTunnelRouteNotFound is returned if there is no route for the network.
+func (api *API) GetTunnelRouteByNetwork(ctx context.Context, rc *ResourceContainer, params GetTunnelRouteByNetworkParams) (TunnelRoute, error) {
+	rc = api.withDefaultAccount(rc)
+
+	if rc.Identifier == "" {
+		return TunnelRoute{}, ErrMissingAccountID
+	}
+
+	if params.Network == "" {
+		return TunnelRoute{}, ErrMissingNetwork
+	}
+
+	if err := validateTunnelRouteNetwork(params.Network); err != nil {
+		return TunnelRoute{}, err
+	}
+
+	routes, _, err := api.ListTunnelRoutesAll(ctx, rc, TunnelRoutesListParams{
+		IsDeleted:        BoolPtr(false),
+		NetworkSubset:    params.Network,
+		NetworkSuperset:  params.Network,
+		VirtualNetworkID: params.VirtualNetworkID,
+	})
+	if err != nil {
+		return TunnelRoute{}, err
+	}
+
+	var matches []TunnelRoute
+	for _, route := range routes {
+		if CompareNetworks(route.Network, params.Network) != 0 {
+			continue
+		}
+
+		if params.VirtualNetworkID != "" && route.VirtualNetworkID != params.VirtualNetworkID {
+			continue
+		}
+
+		matches = append(matches, route)
+	}
+
+	switch len(matches) {
+	case 0:
+		return TunnelRoute{}, fmt.Errorf("%w: %s", ErrTunnelRouteNotFound, params.Network)
+	case 1:
+		return matches[0], nil
+	default:
+		return TunnelRoute{}, fmt.Errorf("%w: %q in %d virtual networks", ErrDuplicateTunnelRoute, params.Network, len(matches))
+	}
+}
+
+// GetTunnelRouteForIP finds the Tunnel Route that encompasses the given IP.
+//
+// See: https://api.cloudflare.com/#tunnel-route-get-tunnel-route-by-ip
+func (api *API) GetTunnelRouteForIP(ctx context.Context, rc *ResourceContainer, params TunnelRoutesForIPParams) (_ TunnelRoute, err error) {
+	rc = api.withDefaultAccount(rc)
+
+	ctx, span := api.startSpan(ctx, "GetTunnelRouteForIP", rc, "")
+	defer func() { endSpan(span, err) }()
+
+	if rc.Identifier == "" {
+		return TunnelRoute{}, ErrMissingAccountID
+	}
+
+	if params.Network == "" {
+		return TunnelRoute{}, ErrMissingNetwork
+	}
+
+	if strings.Contains(params.Network, "/") {
+		return TunnelRoute{}, ErrInvalidNetworkValue
+	}
+
+	ip := net.ParseIP(params.Network)
+	if ip == nil {
+		return TunnelRoute{}, fmt.Errorf("%w: %q", ErrInvalidNetworkValue, params.Network)
+	}
+
+	// The IP is normalised first so that differently formatted
+	// representations of the same address, such as upper case or zero padded
+	// IPv6, resolve consistently.
+	uri := api.buildTeamnetURL(rc.Identifier, "routes", "ip", ip.String()) + buildURI("", params)
+
+	responseBody, err := api.makeRequestContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return TunnelRoute{}, tunnelRoutePermissionError(err, params.Network)
+	}
+
+	var routeResponse tunnelRouteResponse
+	err = api.unmarshalResponse(responseBody, &routeResponse)
+	if err != nil {
+		return TunnelRoute{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+	}
+
+	if err := tunnelRouteResponseError(routeResponse.Response, http.StatusOK); err != nil {
+		return TunnelRoute{}, err
+	}
+
+	return routeResponse.Result, nil
+}
+
+// CreateTunnelRoute add a new route to the account routing table for the given
+// tunnel. ErrTunnelNotFound is returned if the tunnel does not exist.
+//
+// See: https://api.cloudflare.com/#tunnel-route-create-route
+func (api *API) CreateTunnelRoute(ctx context.Context, rc *ResourceContainer, params TunnelRoutesCreateParams) (TunnelRoute, error) {
+	route, _, err := api.CreateTunnelRouteWithResponse(ctx, rc, params)
+	return route, err
+}
+
+// CreateTunnelRouteWithResponse is the same as CreateTunnelRoute however it
+// also returns the underlying response, allowing callers to inspect the
+// status and headers (such as rate limit information) sent by the API.
+func (api *API) CreateTunnelRouteWithResponse(ctx context.Context, rc *ResourceContainer, params TunnelRoutesCreateParams) (_ TunnelRoute, _ *APIResponse, err error) {
+	rc = api.withDefaultAccount(rc)
+
+	ctx, span := api.startSpan(ctx, "CreateTunnelRoute", rc, params.TunnelID)
+	defer func() { endSpan(span, err) }()
+
+	if rc.Identifier == "" {
+		return TunnelRoute{}, nil, ErrMissingAccountID
+	}
+
+	if params.Network == "" {
+		return TunnelRoute{}, nil, ErrMissingNetwork
+	}
+
+	if err := validateTunnelRouteNetwork(params.Network); err != nil {
+		return TunnelRoute{}, nil, err
+	}
+
+	if err := validateTunnelRouteComment(params.Comment); err != nil {
+		return TunnelRoute{}, nil, err
+	}
+
+	uri := api.tunnelRouteNetworkURI(rc, params.Network)
+
+	res, err := api.makeRequestContextWithHeadersComplete(ctx, http.MethodPost, uri, params, nil)
+	api.tunnelRouteCaches.invalidate(rc.Identifier)
+	if err != nil {
+		if isTunnelRouteExistsError(err) {
+			return TunnelRoute{}, nil, &tunnelRouteError{sentinel: ErrTunnelRouteExists, subject: params.Network, err: err}
+		}
+		if params.TunnelID != "" && api.isTunnelNotFoundError(ctx, rc, params.TunnelID, err) {
+			return TunnelRoute{}, nil, &tunnelRouteError{sentinel: ErrTunnelNotFound, subject: params.TunnelID, err: err}
+		}
+		return TunnelRoute{}, nil, tunnelRoutePermissionError(err, params.Network)
+	}
+
+	var routeResponse tunnelRouteResponse
+	err = api.unmarshalResponse(res.Body, &routeResponse)
+	if err != nil {
+		return TunnelRoute{}, res, fmt.Errorf("%s: %w", errUnmarshalError, err)
+	}
+
+	if err := tunnelRouteResponseError(routeResponse.Response, res.StatusCode); err != nil {
+		return TunnelRoute{}, res, err
+	}
+
+	routeResponse.Result.ETag = res.Headers.Get("ETag")
+	return routeResponse.Result, res, nil
+}
+
+// CreateTunnelRouteIfAbsent creates the route unless one already exists for
+// the network in the virtual network, in which case the existing route is
+// returned instead of ErrTunnelRouteExists. The returned bool reports whether
+// the route was created. The existing route is returned as is, so it may
+// belong to a different tunnel or have a different comment than params.
+//
+// Routes created without a VirtualNetworkID are added to the default virtual
+// network, so that is where an existing route is looked up in that case.
+func (api *API) CreateTunnelRouteIfAbsent(ctx context.Context, rc *ResourceContainer, params TunnelRoutesCreateParams) (TunnelRoute, bool, error) {
+	rc = api.withDefaultAccount(rc)
+
+	route, err := api.CreateTunnelRoute(ctx, rc, params)
+	if err == nil {
+		return route, true, nil
+	}
+
+	if !errors.Is(err, ErrTunnelRouteExists) {
+		return TunnelRoute{}, false, err
+	}
+
+	vnetID := params.VirtualNetworkID
+	if vnetID == "" {
+		vnet, err := api.GetDefaultTunnelVirtualNetwork(ctx, rc)
+		if err != nil {
+			return TunnelRoute{}, false, err
+		}
+		vnetID = vnet.ID
+	}
+
+	existing, err := api.GetTunnelRouteByNetwork(ctx, rc, GetTunnelRouteByNetworkParams{Network: params.Network, VirtualNetworkID: vnetID})
+	if err != nil {
+		return TunnelRoute{}, false, err
+	}
+
+	return existing, false, nil
+}
+
+// CreateTunnelHostRoute adds a route for a single host, where params.Network is
+// a bare IP address rather than a CIDR range. The prefix length is added for
+// it, /32 for IPv4 and /128 for IPv6, before calling CreateTunnelRoute.
+// ErrInvalidNetworkValue is returned if the network is not a single IP address.
+func (api *API) CreateTunnelHostRoute(ctx context.Context, rc *ResourceContainer, params TunnelRoutesCreateParams) (TunnelRoute, error) {
+	if params.Network == "" {
+		return TunnelRoute{}, ErrMissingNetwork
+	}
+
+	if strings.Contains(params.Network, "/") {
+		return TunnelRoute{}, fmt.Errorf("%w: %q is a CIDR range, not a single host", ErrInvalidNetworkValue, params.Network)
+	}
+
+	network, ok := hostNetwork(params.Network)
+	if !ok {
+		return TunnelRoute{}, fmt.Errorf("%w: %q", ErrInvalidNetworkValue, params.Network)
+	}
+
+	params.Network = network
+	return api.CreateTunnelRoute(ctx, rc, params)
+}
+
+// PlanCreateTunnelRoute validates the params and returns the request that
+// CreateTunnelRoute would send, without calling the API.
+func (api *API) PlanCreateTunnelRoute(rc *ResourceContainer, params TunnelRoutesCreateParams) (TunnelRouteRequest, error) {
+	rc = api.withDefaultAccount(rc)
+
+	if rc.Identifier == "" {
+		return TunnelRouteRequest{}, ErrMissingAccountID
+	}
+
+	if params.Network == "" {
+		return TunnelRouteRequest{}, ErrMissingNetwork
+	}
+
+	if err := validateTunnelRouteNetwork(params.Network); err != nil {
+		return TunnelRouteRequest{}, err
+	}
+
+	if err := validateTunnelRouteComment(params.Comment); err != nil {
+		return TunnelRouteRequest{}, err
+	}
+
+	return api.planTunnelRouteRequest(http.MethodPost, api.tunnelRouteNetworkURI(rc, params.Network), params)
+}
+
+// DeleteTunnelRoute delete an existing route from the account routing table.
+// The deleted route is returned, including the DeletedAt time set by the API.
+//
+// Routes are soft deleted: the route stops being used immediately and its
+// network can be routed again straight away, but the record is kept and can
+// still be listed using IsDeleted or Deleted, and restored using
+// RestoreTunnelRoute. The API provides no way to permanently delete a route.
+//
+// See: https://api.cloudflare.com/#tunnel-route-delete-route
+func (api *API) DeleteTunnelRoute(ctx context.Context, rc *ResourceContainer, params TunnelRoutesDeleteParams) (_ TunnelRoute, err error) {
+	rc = api.withDefaultAccount(rc)
+
+	ctx, span := api.startSpan(ctx, "DeleteTunnelRoute", rc, "")
+	defer func() { endSpan(span, err) }()
+
+	if rc.Identifier == "" {
+		return TunnelRoute{}, ErrMissingAccountID
+	}
+
+	if params.Network == "" {
+		return TunnelRoute{}, ErrMissingNetwork
+	}
+
+	// Cannot fully utilize buildURI here because it tries to escape "%" sign
+	// from the already escaped "/" sign from Network field.
+	uri := api.tunnelRouteNetworkURI(rc, params.Network) + buildURI("", params)
+
+	responseBody, err := api.makeRequestContext(ctx, http.MethodDelete, uri, nil)
+	api.tunnelRouteCaches.invalidate(rc.Identifier)
+	if err != nil {
+		return TunnelRoute{}, tunnelRoutePermissionError(err, params.Network)
+	}
+
+	var routeResponse tunnelRouteResponse
+	err = api.unmarshalResponse(responseBody, &routeResponse)
+	if err != nil {
+		return TunnelRoute{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+	}
+
+	if err := tunnelRouteResponseError(routeResponse.Response, http.StatusOK); err != nil {
+		return TunnelRoute{}, err
+	}
+
+	return routeResponse.Result, nil
+}
+
+// DeleteTunnelRouteIfExists deletes an existing route from the account routing
+// table, treating a route that does not exist as already deleted. Any other
+// error, such as an authentication failure, is returned as is.
+func (api *API) DeleteTunnelRouteIfExists(ctx context.Context, rc *ResourceContainer, params TunnelRoutesDeleteParams) error {
+	_, err := api.DeleteTunnelRoute(ctx, rc, params)
+	if err != nil {
+		var notFoundError *NotFoundError
+		if errors.As(err, &notFoundError) {
+			return nil
+		}
+		return err
+	}
+
+	return nil
+}
+
+// RestoreTunnelRoute restores a previously deleted route. The API has no
+// dedicated endpoint for this so the most recently deleted route for the
+// network is looked up and recreated using its tunnel, comment and virtual
+// network. The returned route is the newly created one and so has no
+// DeletedAt.
+//
+// ErrTunnelRouteNotFound is returned if there is no deleted route for the
+// network. If another route for the network has since been created, the API
+// rejects the restore and ErrTunnelRouteExists is returned.
+func (api *API) RestoreTunnelRoute(ctx context.Context, rc *ResourceContainer, params TunnelRoutesRestoreParams) (TunnelRoute, error) {
+	rc = api.withDefaultAccount(rc)
+
+	if rc.Identifier == "" {
+		return TunnelRoute{}, ErrMissingAccountID
+	}
+
+	if params.Network == "" {
+		return TunnelRoute{}, ErrMissingNetwork
+	}
+
+	if err := validateTunnelRouteNetwork(params.Network); err != nil {
+		return TunnelRoute{}, err
+	}
+
+	deleted, _, err := api.ListTunnelRoutesAll(ctx, rc, TunnelRoutesListParams{
+		IsDeleted:        BoolPtr(true),
+		NetworkSubset:    params.Network,
+		NetworkSuperset:  params.Network,
+		VirtualNetworkID: params.VirtualNetworkID,
+	})
+	if err != nil {
+		return TunnelRoute{}, err
+	}
+
+	var latest *TunnelRoute
+	for i, route := range deleted {
+		if route.DeletedAt == nil {
+			continue
+		}
+
+		if latest == nil || route.DeletedAt.After(*latest.DeletedAt) {
+			latest = &deleted[i]
+		}
+	}
+
+	if latest == nil {
+		return TunnelRoute{}, fmt.Errorf("%w: no deleted route for %s", ErrTunnelRouteNotFound, params.Network)
+	}
+
+	return api.CreateTunnelRoute(ctx, rc, TunnelRoutesCreateParams{
+		Network:          params.Network,
+		TunnelID:         latest.TunnelID,
+		Comment:          latest.Comment,
+		VirtualNetworkID: latest.VirtualNetworkID,
+	})
+}
+
+// UpdateTunnelRoute updates an existing route in the account routing table for
+// the given tunnel. ErrTunnelNotFound is returned if the tunnel being assigned
+// does not exist.
+//
+// See: https://api.cloudflare.com/#tunnel-route-update-route
+func (api *API) UpdateTunnelRoute(ctx context.Context, rc *ResourceContainer, params TunnelRoutesUpdateParams) (TunnelRoute, error) {
+	route, _, err := api.UpdateTunnelRouteWithResponse(ctx, rc, params)
+	return route, err
+}
+
+// UpdateTunnelRouteWithResponse is the same as UpdateTunnelRoute however it
+// also returns the underlying response, allowing callers to inspect the
+// status and headers (such as rate limit information) sent by the API.
+func (api *API) UpdateTunnelRouteWithResponse(ctx context.Context, rc *ResourceContainer, params TunnelRoutesUpdateParams) (TunnelRoute, *APIResponse, error) {
+	return api.updateTunnelRoute(ctx, rc, params, params)
+}
+
+// updateTunnelRoute validates params and sends body as the update of the
+// route, allowing a body which sets fields params would omit.
+func (api *API) updateTunnelRoute(ctx context.Context, rc *ResourceContainer, params TunnelRoutesUpdateParams, body interface{}) (_ TunnelRoute, _ *APIResponse, err error) {
+	rc = api.withDefaultAccount(rc)
+
+	ctx, span := api.startSpan(ctx, "UpdateTunnelRoute", rc, params.TunnelID)
+	defer func() { endSpan(span, err) }()
+
+	if rc.Identifier == "" {
+		return TunnelRoute{}, nil, ErrMissingAccountID
+	}
+
+	if params.Network == "" {
+		return TunnelRoute{}, nil, ErrMissingNetwork
+	}
+
+	if err := validateTunnelRouteNetwork(params.Network); err != nil {
+		return TunnelRoute{}, nil, err
+	}
+
+	if err := validateTunnelRouteComment(params.Comment); err != nil {
+		return TunnelRoute{}, nil, err
+	}
+
+	uri := api.tunnelRouteNetworkURI(rc, params.Network)
+
+	var headers http.Header
+	if params.IfMatch != "" {
+		headers = http.Header{"If-Match": []string{params.IfMatch}}
+	}
+
+	res, err := api.makeRequestContextWithHeadersComplete(ctx, http.MethodPatch, uri, body, headers)
+	api.tunnelRouteCaches.invalidate(rc.Identifier)
+	if err != nil {
+		if isTunnelRouteConflictError(err) {
+			return TunnelRoute{}, nil, &tunnelRouteError{sentinel: ErrTunnelRouteConflict, subject: params.Network, err: err}
+		}
+		if params.TunnelID != "" && api.isTunnelNotFoundError(ctx, rc, params.TunnelID, err) {
+			return TunnelRoute{}, nil, &tunnelRouteError{sentinel: ErrTunnelNotFound, subject: params.TunnelID, err: err}
+		}
+		return TunnelRoute{}, nil, tunnelRoutePermissionError(err, params.Network)
+	}
+
+	var routeResponse tunnelRouteResponse
+	err = api.unmarshalResponse(res.Body, &routeResponse)
+	if err != nil {
+		return TunnelRoute{}, res, fmt.Errorf("%s: %w", errUnmarshalError, err)
+	}
+
+	if err := tunnelRouteResponseError(routeResponse.Response, res.StatusCode); err != nil {
+		return TunnelRoute{}, res, err
+	}
+
+	routeResponse.Result.ETag = res.Headers.Get("ETag")
+	return routeResponse.Result, res, nil
+}
+
+// PlanUpdateTunnelRoute validates the params and returns the request that
+// UpdateTunnelRoute would send, without calling the API.
+func (api *API) PlanUpdateTunnelRoute(rc *ResourceContainer, params TunnelRoutesUpdateParams) (TunnelRouteRequest, error) {
+	rc = api.withDefaultAccount(rc)
+
+	if rc.Identifier == "" {
+		return TunnelRouteRequest{}, ErrMissingAccountID
+	}
+
+	if params.Network == "" {
+		return TunnelRouteRequest{}, ErrMissingNetwork
+	}
+
+	if err := validateTunnelRouteNetwork(params.Network); err != nil {
+		return TunnelRouteRequest{}, err
+	}
+
+	if err := validateTunnelRouteComment(params.Comment); err != nil {
+		return TunnelRouteRequest{}, err
+	}
+
+	return api.planTunnelRouteRequest(http.MethodPatch, api.tunnelRouteNetworkURI(rc, params.Network), params)
+}
+
+func (api *API) planTunnelRouteRequest(method, uri string, params interface{}) (TunnelRouteRequest, error) {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return TunnelRouteRequest{}, fmt.Errorf("error marshalling params to JSON: %w", err)
+	}
+
+	return TunnelRouteRequest{Method: method, URL: api.BaseURL + uri, Body: body}, nil
+}
+
+// tunnelRouteNetworkURI returns the URI for operating on the route of a
+// network.
+func (api *API) tunnelRouteNetworkURI(rc *ResourceContainer, network string) string {
+	return api.buildTeamnetURL(rc.Identifier, "routes", "network", network)
+}
+
+// buildTeamnetURL returns the URI of a teamnet resource within the account,
+// escaping each of the segments. url.PathEscape only escapes characters such
+// as the "/" separating the prefix length of a network; the colons of IPv6
+// addresses are valid path characters and are left as is.
+func (api *API) buildTeamnetURL(accountID string, segments ...string) string {
+	root := AccountRouteRoot
+	if api.accountRouteRoot != "" {
+		root = api.accountRouteRoot
+	}
+
+	var b strings.Builder
+	b.WriteString("/")
+	b.WriteString(string(root))
+	b.WriteString("/")
+	b.WriteString(url.PathEscape(accountID))
+	b.WriteString("/teamnet")
+	for _, segment := range segments {
+		b.WriteString("/")
+		b.WriteString(url.PathEscape(segment))
+	}
+
+	return b.String()
+}
+
+// UpdateTunnelRouteCommentParams holds the route to update and its new
+// comment.
+type UpdateTunnelRouteCommentParams struct {
+	Network          string `json:"network"`
+	VirtualNetworkID string `json:"virtual_network_id,omitempty"`
+	// Comment replaces the existing comment. It is always sent, so an empty
+	// Comment clears the existing one.
+	Comment string `json:"comment"`
+}
+
+// UpdateTunnelRouteComment updates only the comment of an existing route,
+// leaving the tunnel it is assigned to untouched. Unlike UpdateTunnelRoute it
+// can be used to clear the comment.
+func (api *API) UpdateTunnelRouteComment(ctx context.Context, rc *ResourceContainer, params UpdateTunnelRouteCommentParams) (TunnelRoute, error) {
+	route, _, err := api.updateTunnelRoute(ctx, rc, TunnelRoutesUpdateParams{
+		Network:          params.Network,
+		Comment:          params.Comment,
+		VirtualNetworkID: params.VirtualNetworkID,
+	}, params)
+	return route, err
+}
+
+// TunnelRouteOrphanReason describes why a route is orphaned.
+type TunnelRouteOrphanReason string
+
+const (
+	// TunnelRouteOrphanTunnelDeleted is used when the tunnel of the route has
+	// been deleted.
+	TunnelRouteOrphanTunnelDeleted TunnelRouteOrphanReason = "tunnel_deleted"
+	// TunnelRouteOrphanTunnelNotFound is used when the tunnel of the route
+	// does not exist at all.
+	TunnelRouteOrphanTunnelNotFound TunnelRouteOrphanReason = "tunnel_not_found"
+)
+
+// OrphanedTunnelRoute is a route whose tunnel no longer exists, as returned by
+// ValidateTunnelRoutes.
+type OrphanedTunnelRoute struct {
+	Route  TunnelRoute
+	Reason TunnelRouteOrphanReason
+}
+
+// ValidateTunnelRoutes returns the active routes of the account which point at
+// a cloudflared or WARP Connector tunnel that has been deleted or does not
+// exist. Routes for other types of tunnel are not checked.
+func (api *API) ValidateTunnelRoutes(ctx context.Context, rc *ResourceContainer) ([]OrphanedTunnelRoute, error) {
+	rc = api.withDefaultAccount(rc)
+
+	if rc.Identifier == "" {
+		return nil, ErrMissingAccountID
+	}
+
+	routes, _, err := api.ListTunnelRoutesAll(ctx, rc, TunnelRoutesListParams{Deleted: TunnelRoutesActive})
+	if err != nil {
+		return nil, err
+	}
+
+	// Deleted tunnels are included so that the reason can distinguish them
+	// from tunnels which never existed.
+	tunnels, _, err := api.ListTunnels(ctx, rc, TunnelListParams{})
+	if err != nil {
+		return nil, err
+	}
+
+	connectors, _, err := api.ListWarpConnectorTunnels(ctx, rc, TunnelListParams{})
+	if err != nil {
+		return nil, err
+	}
+
+	tunnelsByID := make(map[string]Tunnel, len(tunnels)+len(connectors))
+	for _, tunnel := range append(tunnels, connectors...) {
+		tunnelsByID[tunnel.ID] = tunnel
+	}
+
+	var orphaned []OrphanedTunnelRoute
+	for _, route := range routes {
+		if route.TunnelType != "" && route.TunnelType != TunnelRouteTypeCloudflared && route.TunnelType != TunnelRouteTypeWarpConnector {
+			continue
+		}
+
+		tunnel, ok := tunnelsByID[route.TunnelID]
+		switch {
+		case !ok:
+			orphaned = append(orphaned, OrphanedTunnelRoute{Route: route, Reason: TunnelRouteOrphanTunnelNotFound})
+		case tunnel.DeletedAt != nil:
+			orphaned = append(orphaned, OrphanedTunnelRoute{Route: route, Reason: TunnelRouteOrphanTunnelDeleted})
+		}
+	}
+
+	return orphaned, nil
+}
+
+// tunnelRouteMetadataMarker precedes the JSON object holding the metadata of a
+// route at the end of its comment.
+const tunnelRouteMetadataMarker = "meta:"
+
+// SetTunnelRouteMetadata returns comment with its metadata block replaced by
+// meta, leaving any free text intact. The API has no field for arbitrary
+// metadata so it is stored as a JSON object at the end of the comment, for
+// example "web servers meta:{"env":"prod"}". An empty meta removes the block.
+// ErrCommentTooLong is returned when the resulting comment would be longer
+// than the API accepts.
+func SetTunnelRouteMetadata(comment string, meta map[string]string) (string, error) {
+	text := strings.TrimSpace(splitTunnelRouteComment(comment))
+	if len(meta) > 0 {
+		encoded, err := json.Marshal(meta)
+		if err != nil {
+			return "", err
+		}
+
+		block := tunnelRouteMetadataMarker + string(encoded)
+		if text == "" {
+			text = block
+		} else {
+			text += " " + block
+		}
+	}
+
+	if err := validateTunnelRouteComment(text); err != nil {
+		return "", err
+	}
+
+	return text, nil
+}
+
+// ParseTunnelRouteMetadata returns the metadata stored in comment by
+// SetTunnelRouteMetadata and whether there was any.
+func ParseTunnelRouteMetadata(comment string) (map[string]string, bool) {
+	text := splitTunnelRouteComment(comment)
+	if text == comment {
+		return nil, false
+	}
+
+	var meta map[string]string
+	if err := json.Unmarshal([]byte(comment[len(text)+len(tunnelRouteMetadataMarker):]), &meta); err != nil {
+		return nil, false
+	}
+
+	return meta, true
+}
+
+// splitTunnelRouteComment returns the free text of comment, without any
+// metadata block. The comment is returned as is when it has no valid block.
+func splitTunnelRouteComment(comment string) string {
+	i := strings.LastIndex(comment, tunnelRouteMetadataMarker+"{")
+	if i < 0 {
+		return comment
+	}
+
+	var meta map[string]string
+	if err := json.Unmarshal([]byte(comment[i+len(tunnelRouteMetadataMarker):]), &meta); err != nil {
+		return comment
+	}
+
+	return comment[:i]
+}
+
+// DiffTunnelRoutes compares the desired routes against the current routes and
+// returns the changes needed to reconcile them. Routes are matched by their
+// network and virtual network, and a matched route needs updating when its
+// tunnel or comment differs. IDs and timestamps are ignored when comparing.
+//
+// Routes to update are returned as desired but carry the ID of the current
+// route. The order of desired is preserved for routes to create and update
+// and the order of current for routes to delete.
+func DiffTunnelRoutes(desired, current []TunnelRoute) (toCreate, toUpdate, toDelete []TunnelRoute) {
+	existing := make(map[TunnelRouteKey]TunnelRoute, len(current))
+	for _, route := range current {
+		existing[route.Key()] = route
+	}
+
+	wanted := make(map[TunnelRouteKey]struct{}, len(desired))
+	for _, route := range desired {
+		key := route.Key()
+		wanted[key] = struct{}{}
+
+		found, ok := existing[key]
+		if !ok {
+			toCreate = append(toCreate, route)
+			continue
+		}
+
+		if found.TunnelID != route.TunnelID || found.Comment != route.Comment {
+			route.ID = found.ID
+			toUpdate = append(toUpdate, route)
+		}
+	}
+
+	for _, route := range current {
+		if _, ok := wanted[route.Key()]; !ok {
+			toDelete = append(toDelete, route)
+		}
+	}
+
+	return toCreate, toUpdate, toDelete
+}
+
+// ActiveTunnelRoutes returns the routes which have not been deleted, keeping
+// their order. It guards against deleted routes appearing in results that
+// were not filtered using IsDeleted or Deleted.
+func ActiveTunnelRoutes(routes []TunnelRoute) []TunnelRoute {
+	active := make([]TunnelRoute, 0, len(routes))
+	for _, route := range routes {
+		if !route.IsDeleted() {
+			active = append(active, route)
+		}
+	}
+
+	return active
+}
+
+// GroupTunnelRoutesByTunnel groups the routes by TunnelID, keeping their order
+// within each group.
+func GroupTunnelRoutesByTunnel(routes []TunnelRoute) map[string][]TunnelRoute {
+	groups := make(map[string][]TunnelRoute)
+	for _, route := range routes {
+		groups[route.TunnelID] = append(groups[route.TunnelID], route)
+	}
+
+	return groups
+}
+
+// GroupTunnelRoutesByTunnelName groups the routes by TunnelName, keeping their
+// order within each group. Tunnels sharing a name are grouped together.
+func GroupTunnelRoutesByTunnelName(routes []TunnelRoute) map[string][]TunnelRoute {
+	groups := make(map[string][]TunnelRoute)
+	for _, route := range routes {
+		groups[route.TunnelName] = append(groups[route.TunnelName], route)
+	}
+
+	return groups
+}
+
+// TunnelRouteGroup is the routes of a single tunnel.
+type TunnelRouteGroup struct {
+	TunnelID   string
+	TunnelName string
+	Routes     []TunnelRoute
+}
+
+// TunnelRouteGroups groups the routes by tunnel like GroupTunnelRoutesByTunnel
+// but returns the groups ordered by tunnel name and then ID, so that they can
+// be iterated deterministically, for example when writing reports.
+func TunnelRouteGroups(routes []TunnelRoute) []TunnelRouteGroup {
+	byTunnel := GroupTunnelRoutesByTunnel(routes)
+
+	groups := make([]TunnelRouteGroup, 0, len(byTunnel))
+	for tunnelID, tunnelRoutes := range byTunnel {
+		groups = append(groups, TunnelRouteGroup{
+			TunnelID:   tunnelID,
+			TunnelName: tunnelRoutes[0].TunnelName,
+			Routes:     tunnelRoutes,
+		})
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].TunnelName != groups[j].TunnelName {
+			return groups[i].TunnelName < groups[j].TunnelName
+		}
+		return groups[i].TunnelID < groups[j].TunnelID
+	})
+
+	return groups
+}
+
+// FindOverlappingTunnelRoutes returns the routes whose network overlaps that of
+// the candidate, allowing a set of routes to be validated before any are sent
+// to the API. Routes only overlap within the same virtual network. As an empty
+// VirtualNetworkID refers to the account's default virtual network, whatever
+// that happens to be, it is considered to overlap with every virtual network.
+func FindOverlappingTunnelRoutes(routes []TunnelRoute, candidate TunnelRoute) ([]TunnelRoute, error) {
+	_, candidateNet, err := net.ParseCIDR(candidate.Network)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidNetworkCIDR, candidate.Network)
+	}
+
+	var overlapping []TunnelRoute
+	for _, route := range routes {
+		if route.VirtualNetworkID != "" && candidate.VirtualNetworkID != "" &&
+			route.VirtualNetworkID != candidate.VirtualNetworkID {
+			continue
+		}
+
+		_, routeNet, err := net.ParseCIDR(route.Network)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidNetworkCIDR, route.Network)
+		}
+
+		if routeNet.Contains(candidateNet.IP) || candidateNet.Contains(routeNet.IP) {
+			overlapping = append(overlapping, route)
+		}
+	}
+
+	return overlapping, nil
+}
+
+// TunnelRoutesWithinNetwork returns the routes whose network lies entirely
+// within supernet, including a network equal to it, keeping their order. It is
+// the client side equivalent of the NetworkSubset list filter and can be run
+// against routes already fetched, such as those held by a TunnelRouteCache.
+// IPv4-mapped IPv6 networks are compared as IPv4.
+//
+// An invalid supernet is an error. Routes whose network cannot be parsed are
+// not silently dropped; the routes which did match are returned along with an
+// error wrapping ErrInvalidNetworkCIDR that lists the invalid networks.
+func TunnelRoutesWithinNetwork(routes []TunnelRoute, supernet string) ([]TunnelRoute, error) {
+	_, super, err := net.ParseCIDR(supernet)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidNetworkCIDR, supernet)
+	}
+	superIP, superOnes, superBits := normalizeIPNet(super)
+
+	within := []TunnelRoute{}
+	var invalid []string
+	for _, route := range routes {
+		_, network, err := net.ParseCIDR(route.Network)
+		if err != nil {
+			invalid = append(invalid, strconv.Quote(route.Network))
+			continue
+		}
+
+		ip, ones, bits := normalizeIPNet(network)
+		if bits != superBits || ones < superOnes {
+			continue
+		}
+
+		if ip.Mask(net.CIDRMask(superOnes, superBits)).Equal(superIP) {
+			within = append(within, route)
+		}
+	}
+
+	if len(invalid) > 0 {
+		return within, fmt.Errorf("%w: %s", ErrInvalidNetworkCIDR, strings.Join(invalid, ", "))
+	}
+
+	return within, nil
+}
+
+// normalizeIPNet returns the address and prefix length of network, converting
+// IPv4-mapped IPv6 networks to IPv4.
+func normalizeIPNet(network *net.IPNet) (net.IP, int, int) {
+	ones, bits := network.Mask.Size()
+	if ip4 := network.IP.To4(); ip4 != nil {
+		if bits == 8*net.IPv6len {
+			ones -= 8 * (net.IPv6len - net.IPv4len)
+		}
+		return ip4, ones, 8 * net.IPv4len
+	}
+
+	return network.IP, ones, bits
+}
+
+// hostNetwork returns the network containing only the IP address, using a
+// /32 prefix for IPv4 and /128 for IPv6, and whether ip is a valid address.
+func hostNetwork(ip string) (string, bool) {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return "", false
+	}
+
+	if addr.To4() != nil {
+		return fmt.Sprintf("%s/%d", addr, 8*net.IPv4len), true
+	}
+
+	return fmt.Sprintf("%s/%d", addr, 8*net.IPv6len), true
+}
+
+// validateTunnelRouteComment ensures the comment is no longer than the API
+// accepts, rather than sending a request which is bound to be rejected.
+func validateTunnelRouteComment(comment string) error {
+	if n := utf8.RuneCountInString(comment); n > TunnelRouteCommentMaxLength {
+		return fmt.Errorf("%w: %d characters", ErrCommentTooLong, n)
+	}
+
+	return nil
+}
+
+// validateTunnelRouteNetwork ensures the network is a valid CIDR range. Bare IP
+// addresses without a prefix length are rejected.
+func validateTunnelRouteNetwork(network string) error {
+	if _, _, err := net.ParseCIDR(network); err != nil {
+		return fmt.Errorf("%w: %q", ErrInvalidNetworkCIDR, network)
+	}
+
+	return nil
+}
+
+// tunnelRoutesBulkDefaultMaxInFlight is the default number of concurrent
+// requests made by bulk tunnel route operations.
+const tunnelRoutesBulkDefaultMaxInFlight = 4
+
+// BulkCreateTunnelRoutes creates many routes concurrently, bounded by
+// MaxInFlight. A failure to create one route does not stop the others from
+// being created; each outcome is reported in the returned results, which are
+// in the same order as params.Routes. If any route failed, a
+// *TunnelRouteBulkError wrapping the individual failures is also returned.
+func (api *API) BulkCreateTunnelRoutes(ctx context.Context, rc *ResourceContainer, params TunnelRoutesBulkCreateParams) ([]TunnelRouteResult, error) {
+	rc = api.withDefaultAccount(rc)
+
+	if rc.Identifier == "" {
+		return []TunnelRouteResult{}, ErrMissingAccountID
+	}
+
+	results := make([]TunnelRouteResult, len(params.Routes))
+	runTunnelRouteOperations(len(params.Routes), params.MaxInFlight, func(i int) {
+		route, err := api.CreateTunnelRoute(ctx, rc, params.Routes[i])
+		results[i] = TunnelRouteResult{Network: params.Routes[i].Network, Route: route, Err: err}
+	})
+
+	return results, tunnelRouteResultsError(results)
+}
+
+// ImportTunnelRoutes creates the routes read from r, a routing table with a
+// route per line in the form "network tunnel_id [comment]", where everything
+// after the tunnel ID is the comment. Blank lines and lines starting with "#"
+// are skipped. For example:
+//
+//	# office networks
+//	10.0.0.0/16 f70ff985-a4ef-4643-bbbc-4a0ed4fc8415 London office
+//	2001:db8::/32 f70ff985-a4ef-4643-bbbc-4a0ed4fc8415
+//
+// The whole of r is read and validated before any routes are created, which
+// happens concurrently as with BulkCreateTunnelRoutes. A result is returned
+// for every route line, in order, including lines that could not be parsed;
+// if any line failed, a *TunnelRouteBulkError is also returned whose
+// failures are prefixed with their line numbers.
+func (api *API) ImportTunnelRoutes(ctx context.Context, rc *ResourceContainer, r io.Reader) ([]TunnelRouteImportResult, error) {
+	rc = api.withDefaultAccount(rc)
+
+	if rc.Identifier == "" {
+		return []TunnelRouteImportResult{}, ErrMissingAccountID
+	}
+
+	var results []TunnelRouteImportResult
+	var routes []TunnelRoutesCreateParams
+	var routeResults []int
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		result := TunnelRouteImportResult{Line: line}
+		fields := strings.Fields(text)
+		result.Network = fields[0]
+
+		if len(fields) < 2 {
+			result.Err = ErrInvalidTunnelRouteImportLine
+		} else {
+			result.Err = validateTunnelRouteNetwork(fields[0])
+		}
+
+		if result.Err == nil {
+			// the tunnel ID directly follows the network, and the comment is
+			// everything after it with its inner spacing left untouched
+			rest := strings.TrimSpace(text[len(fields[0]):])
+			routeResults = append(routeResults, len(results))
+			routes = append(routes, TunnelRoutesCreateParams{
+				Network:  fields[0],
+				TunnelID: fields[1],
+				Comment:  strings.TrimSpace(rest[len(fields[1]):]),
+			})
+		}
+
+		results = append(results, result)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return []TunnelRouteImportResult{}, err
+	}
+
+	runTunnelRouteOperations(len(routes), 0, func(i int) {
+		route, err := api.CreateTunnelRoute(ctx, rc, routes[i])
+		results[routeResults[i]].Route = route
+		results[routeResults[i]].Err = err
+	})
+
+	var errs []error
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %s: %w", result.Line, result.Network, result.Err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return results, &TunnelRouteBulkError{Errors: errs}
+	}
+
+	return results, nil
+}
+
+// DeleteTunnelRoutesByFilter deletes every active route matching
+// params.Filter, concurrently bounded by MaxInFlight. params.Confirm must be
+// set. The outcome of each deletion is reported in the returned results and if
+// any failed, a *TunnelRouteBulkError wrapping the individual failures is also
+// returned.
+func (api *API) DeleteTunnelRoutesByFilter(ctx context.Context, rc *ResourceContainer, params TunnelRoutesDeleteByFilterParams) ([]TunnelRouteResult, error) {
+	rc = api.withDefaultAccount(rc)
+
+	if rc.Identifier == "" {
+		return []TunnelRouteResult{}, ErrMissingAccountID
+	}
+
+	if !params.Confirm {
+		return []TunnelRouteResult{}, ErrDeleteNotConfirmed
+	}
+
+	filter := params.Filter
+	filter.Deleted = TunnelRoutesActive
+
+	routes, _, err := api.ListTunnelRoutesAll(ctx, rc, filter)
+	if err != nil {
+		return []TunnelRouteResult{}, err
+	}
+
+	results := make([]TunnelRouteResult, len(routes))
+	runTunnelRouteOperations(len(routes), params.MaxInFlight, func(i int) {
+		_, err := api.DeleteTunnelRoute(ctx, rc, TunnelRoutesDeleteParams{
+			Network:          routes[i].Network,
+			VirtualNetworkID: routes[i].VirtualNetworkID,
+		})
+		results[i] = TunnelRouteResult{Network: routes[i].Network, Route: routes[i], Err: err}
+	})
+
+	return results, tunnelRouteResultsError(results)
+}
+
+// DeleteTunnelWithRoutes deletes every active route to a tunnel and then the
+// tunnel itself, so that no routes are left pointing at a deleted tunnel. The
+// tunnel is deleted even if some of its routes could not be; the outcome of
+// each route deletion is reported in the returned results and any failures,
+// including that of the tunnel, are returned as a *TunnelRouteBulkError.
+func (api *API) DeleteTunnelWithRoutes(ctx context.Context, rc *ResourceContainer, tunnelID string) ([]TunnelRouteResult, error) {
+	rc = api.withDefaultAccount(rc)
+
+	if rc.Identifier == "" {
+		return []TunnelRouteResult{}, ErrMissingAccountID
+	}
+
+	if tunnelID == "" {
+		return []TunnelRouteResult{}, ErrMissingTunnelID
+	}
+
+	results, err := api.DeleteTunnelRoutesByFilter(ctx, rc, TunnelRoutesDeleteByFilterParams{
+		Filter:  TunnelRoutesListParams{TunnelID: tunnelID},
+		Confirm: true,
+	})
+
+	var errs []error
+	var bulkErr *TunnelRouteBulkError
+	switch {
+	case errors.As(err, &bulkErr):
+		errs = append(errs, bulkErr.Errors...)
+	case err != nil:
+		// The routes could not be listed, so deleting the tunnel now could
+		// leave an unknown number of routes behind.
+		return results, err
+	}
+
+	if err := api.DeleteTunnel(ctx, rc, tunnelID); err != nil {
+		errs = append(errs, fmt.Errorf("tunnel %s: %w", tunnelID, err))
+	}
+
+	if len(errs) == 0 {
+		return results, nil
+	}
+
+	return results, &TunnelRouteBulkError{Errors: errs}
+}
+
+// ReplaceTunnelRoutes makes the routes of a tunnel match params.Routes by
+// creating, updating and deleting routes as needed. Routes belonging to other
+// tunnels are never modified. Calling it repeatedly with the same params is
+// safe; once converged no changes are made. Existing comments are left in
+// place when the desired comment is empty.
+//
+// Routes without a VirtualNetworkID are matched against the routes of the
+// default virtual network, which is where the API adds them.
+//
+// New and updated routes are applied before any routes are deleted. Should an
+// operation fail, the changes made so far are returned along with the error.
+// See MaxDeletes for limiting how many routes may be deleted.
+func (api *API) ReplaceTunnelRoutes(ctx context.Context, rc *ResourceContainer, params TunnelRoutesReplaceParams) (TunnelRoutesReplaceResult, error) {
+	rc = api.withDefaultAccount(rc)
+
+	var result TunnelRoutesReplaceResult
+
+	if rc.Identifier == "" {
+		return result, ErrMissingAccountID
+	}
+
+	if params.TunnelID == "" {
+		return result, ErrMissingTunnelID
+	}
+
+	desired := make([]TunnelRoute, 0, len(params.Routes))
+	defaultVnetID := ""
+	for _, route := range params.Routes {
+		if route.Network == "" {
+			return result, ErrMissingNetwork
+		}
+
+		if err := validateTunnelRouteNetwork(route.Network); err != nil {
+			return result, err
+		}
+
+		// listed routes always carry their virtual network, so one must be
+		// filled in for the desired routes to match them
+		if route.VirtualNetworkID == "" && defaultVnetID == "" {
+			vnet, err := api.GetDefaultTunnelVirtualNetwork(ctx, rc)
+			if err != nil {
+				return result, err
+			}
+			defaultVnetID = vnet.ID
+		}
+		if route.VirtualNetworkID == "" {
+			route.VirtualNetworkID = defaultVnetID
+		}
+
+		desired = append(desired, TunnelRoute{
+			Network:          route.Network,
+			TunnelID:         params.TunnelID,
+			Comment:          route.Comment,
+			VirtualNetworkID: route.VirtualNetworkID,
+		})
+	}
+
+	current, _, err := api.ListTunnelRoutesAll(ctx, rc, TunnelRoutesListParams{
+		TunnelID:  params.TunnelID,
+		IsDeleted: BoolPtr(false),
+	})
+	if err != nil {
+		return result, err
+	}
+
+	toCreate, toUpdate, toDelete := DiffTunnelRoutes(desired, current)
+
+	if !params.Force {
+		if params.MaxDeletes > 0 && len(toDelete) > params.MaxDeletes {
+			return result, fmt.Errorf("%w: %d routes would be deleted, at most %d allowed", ErrTooManyDeletions, len(toDelete), params.MaxDeletes)
+		}
+
+		if params.MaxDeletePercent > 0 && len(current) > 0 {
+			if percent := 100 * float64(len(toDelete)) / float64(len(current)); percent > params.MaxDeletePercent {
+				return result, fmt.Errorf("%w: %.1f%% of routes would be deleted, at most %.1f%% allowed", ErrTooManyDeletions, percent, params.MaxDeletePercent)
+			}
+		}
+	}
+
+	for _, route := range toCreate {
+		created, err := api.CreateTunnelRoute(ctx, rc, TunnelRoutesCreateParams{
+			Network:          route.Network,
+			TunnelID:         route.TunnelID,
+			Comment:          route.Comment,
+			VirtualNetworkID: route.VirtualNetworkID,
+		})
+		if err != nil {
+			return result, err
+		}
+		result.Created = append(result.Created, created)
+	}
+
+	for _, route := range toUpdate {
+		// Only the comment can differ as every route belongs to the tunnel,
+		// and an empty comment cannot be sent to clear an existing one.
+		if route.Comment == "" {
+			continue
+		}
+
+		updated, err := api.UpdateTunnelRoute(ctx, rc, TunnelRoutesUpdateParams{
+			Network:          route.Network,
+			TunnelID:         route.TunnelID,
+			Comment:          route.Comment,
+			VirtualNetworkID: route.VirtualNetworkID,
+		})
+		if err != nil {
+			return result, err
+		}
+		result.Updated = append(result.Updated, updated)
+	}
+
+	for _, route := range toDelete {
+		_, err := api.DeleteTunnelRoute(ctx, rc, TunnelRoutesDeleteParams{
+			Network:          route.Network,
+			VirtualNetworkID: route.VirtualNetworkID,
+		})
+		if err != nil {
+			return result, err
+		}
+		result.Deleted = append(result.Deleted, route)
+	}
+
+	return result, nil
+}
+
+// runTunnelRouteOperations calls fn for every index in [0, n) using at most
+// maxInFlight goroutines at once and waits for all of them to finish.
+func runTunnelRouteOperations(n, maxInFlight int, fn func(i int)) {
+	if maxInFlight < 1 {
+		maxInFlight = tunnelRoutesBulkDefaultMaxInFlight
+	}
+
+	sem := make(chan struct{}, maxInFlight)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// tunnelRouteResultsError collects the failures from results into a
+// *TunnelRouteBulkError, returning nil if every operation succeeded.
+func tunnelRouteResultsError(results []TunnelRouteResult) error {
+	var errs []error
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", result.Network, result.Err))
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return &TunnelRouteBulkError{Errors: errs}
+}
+
+// tunnelRouteError associates an error returned by the API with one of the
+// tunnel route sentinel errors so that callers can match it with errors.Is
+// while still being able to inspect the underlying API error.
+type tunnelRouteError struct {
+	sentinel error
+	// subject is the network or tunnel ID the error relates to, if any.
+	subject string
+	err     error
+}
+
+func (e *tunnelRouteError) Error() string {
+	if e.subject == "" {
+		return fmt.Sprintf("%s: %s", e.sentinel, e.err)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", e.sentinel, e.subject, e.err)
+}
+
+func (e *tunnelRouteError) Is(target error) bool {
+	return target == e.sentinel
+}
+
+func (e *tunnelRouteError) Unwrap() error {
+	return e.err
+}
+
+// tunnelRoutePermissionError associates a 403 response with
+// ErrTunnelRoutePermission, as the API only reports that authentication
+// failed without saying which permission the token lacks.
+func tunnelRoutePermissionError(err error, network string) error {
+	var authErr *AuthenticationError
+	if errors.As(err, &authErr) {
+		return &tunnelRouteError{sentinel: ErrTunnelRoutePermission, subject: network, err: err}
+	}
+
+	return err
+}
+
+// tunnelRouteResponseError returns a *RequestError exposing the error codes
+// and messages when the API reports a request as unsuccessful despite
+// responding with a successful status code.
+func tunnelRouteResponseError(resp Response, statusCode int) error {
+	if resp.Success {
+		return nil
+	}
+
+	errCodes := make([]int, 0, len(resp.Errors))
+	errMsgs := make([]string, 0, len(resp.Errors))
+	for _, e := range resp.Errors {
+		errCodes = append(errCodes, e.Code)
+		errMsgs = append(errMsgs, e.Message)
+	}
+
+	return &RequestError{cloudflareError: &Error{
+		Type:          ErrorTypeRequest,
+		StatusCode:    statusCode,
+		Errors:        resp.Errors,
+		ErrorCodes:    errCodes,
+		ErrorMessages: errMsgs,
+		Messages:      resp.Messages,
+	}}
+}
+
+// IsTunnelRouteError returns whether err was returned by the API with the given
+// error code, such as TunnelRouteErrorCodeExists.
+func IsTunnelRouteError(err error, code int) bool {
+	var apiErr interface{ InternalErrorCodeIs(int) bool }
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	return apiErr.InternalErrorCodeIs(code)
+}
+
+// isTunnelRouteConflictError returns whether the API rejected a conditional
+// update because the route no longer matched the If-Match header.
+func isTunnelRouteConflictError(err error) bool {
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) {
+		return false
+	}
+
+	return reqErr.cloudflareError.StatusCode == http.StatusPreconditionFailed
+}
+
+// isTunnelNotFoundError returns whether the API rejected a route because its
+// tunnel does not exist. A missing route is also reported as a 404 and the API
+// has no error code to tell the two apart, so after a 404 the tunnel itself is
+// looked up, as both a cloudflared and a WARP connector tunnel since routes
+// can belong to either. This is best-effort: it costs extra requests and any
+// error looking up the tunnel is treated as the tunnel existing.
+func (api *API) isTunnelNotFoundError(ctx context.Context, rc *ResourceContainer, tunnelID string, err error) bool {
+	if !IsNotFound(err) {
+		return false
+	}
+
+	if _, err := api.GetTunnel(ctx, rc, tunnelID); !IsNotFound(err) {
+		return false
+	}
+
+	_, err = api.GetWarpConnectorTunnel(ctx, rc, tunnelID)
+	return IsNotFound(err)
+}
+
+// isTunnelRouteExistsError returns whether the API rejected a route because
+// one already exists for the network.
+func isTunnelRouteExistsError(err error) bool {
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) {
+		return false
+	}
+
+	return reqErr.cloudflareError.StatusCode == http.StatusConflict || reqErr.InternalErrorCodeIs(TunnelRouteErrorCodeExists)
 }
@@ -1,13 +1,20 @@
 package cloudflare
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/goccy/go-json"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -24,6 +31,7 @@ func TestListTunnelRoutes(t *testing.T) {
 			"messages": [],
 			"result": [
 			  {
+			    "id": "e2ba5a4e-9a52-4c02-a5a6-1b1ad0ee0f6b",
 			    "network": "ff01::/32",
 				"tunnel_id": "f70ff985-a4ef-4643-bbbc-4a0ed4fc8415",
 				"tunnel_name": "blog",
@@ -32,7 +40,14 @@ func TestListTunnelRoutes(t *testing.T) {
 				"deleted_at": "2021-01-25T18:22:34.317854Z",
 				"virtual_network_id": "9f322de4-5988-4945-b770-f1d6ac200f86"
               }
-            ]
+            ],
+			"result_info": {
+			  "page": 1,
+			  "per_page": 20,
+			  "count": 1,
+			  "total_count": 1,
+			  "total_pages": 1
+			}
           }`)
 	}
 
@@ -41,19 +56,163 @@ func TestListTunnelRoutes(t *testing.T) {
 	ts, _ := time.Parse(time.RFC3339Nano, "2021-01-25T18:22:34.317854Z")
 	want := []TunnelRoute{
 		{
-			"ff01::/32",
-			"f70ff985-a4ef-4643-bbbc-4a0ed4fc8415",
-			"blog",
-			"Example comment for this route",
-			&ts,
-			&ts,
-			"9f322de4-5988-4945-b770-f1d6ac200f86",
+			ID:               "e2ba5a4e-9a52-4c02-a5a6-1b1ad0ee0f6b",
+			Network:          "ff01::/32",
+			TunnelID:         "f70ff985-a4ef-4643-bbbc-4a0ed4fc8415",
+			TunnelName:       "blog",
+			Comment:          "Example comment for this route",
+			CreatedAt:        &ts,
+			DeletedAt:        &ts,
+			VirtualNetworkID: "9f322de4-5988-4945-b770-f1d6ac200f86",
 		},
 	}
 
+	wantInfo := &ResultInfo{Page: 1, PerPage: 20, Count: 1, Total: 1, TotalPages: 1}
+
 	params := TunnelRoutesListParams{}
-	got, err := client.ListTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), params)
+	got, resultInfo, err := client.ListTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), params)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, want, got)
+		assert.Equal(t, wantInfo, resultInfo)
+	}
+}
+
+func TestListTunnelRoutesAll(t *testing.T) {
+	setup()
+	defer teardown()
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		assert.Equal(t, "1", r.URL.Query().Get("per_page"))
+		w.Header().Set("content-type", "application/json")
+
+		switch r.URL.Query().Get("page") {
+		case "1":
+			fmt.Fprintf(w, `{
+				"success": true,
+				"errors": [],
+				"messages": [],
+				"result": [
+				  {
+				    "network": "10.0.0.0/16",
+				    "tunnel_id": "f70ff985-a4ef-4643-bbbc-4a0ed4fc8415"
+				  }
+				],
+				"result_info": {"page": 1, "per_page": 1, "count": 1, "total_count": 2, "total_pages": 2}
+			  }`)
+		case "2":
+			fmt.Fprintf(w, `{
+				"success": true,
+				"errors": [],
+				"messages": [],
+				"result": [
+				  {
+				    "network": "10.1.0.0/16",
+				    "tunnel_id": "f70ff985-a4ef-4643-bbbc-4a0ed4fc8415"
+				  }
+				],
+				"result_info": {"page": 2, "per_page": 1, "count": 1, "total_count": 2, "total_pages": 2}
+			  }`)
+		default:
+			assert.Failf(t, "Unexpected page requested", "page %s", r.URL.Query().Get("page"))
+		}
+	}
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", handler)
+
+	want := []TunnelRoute{
+		{Network: "10.0.0.0/16", TunnelID: "f70ff985-a4ef-4643-bbbc-4a0ed4fc8415"},
+		{Network: "10.1.0.0/16", TunnelID: "f70ff985-a4ef-4643-bbbc-4a0ed4fc8415"},
+	}
+
+	params := TunnelRoutesListParams{PaginationOptions: PaginationOptions{PerPage: 1, Page: 5}}
+	got, resultInfo, err := client.ListTunnelRoutesAll(context.Background(), AccountIdentifier(testAccountID), params)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, want, got)
+		assert.Equal(t, 2, resultInfo.Total)
+	}
+}
+
+func TestListTunnelRoutesAll_ContextCancelled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") != "1" {
+			cancel()
+			<-r.Context().Done()
+			return
+		}
+
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": [
+			  {
+			    "network": "10.0.0.0/16",
+			    "tunnel_id": "f70ff985-a4ef-4643-bbbc-4a0ed4fc8415"
+			  }
+			],
+			"result_info": {"page": 1, "per_page": 1, "count": 1, "total_count": 2, "total_pages": 2}
+		  }`)
+	}
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", handler)
+
+	got, _, err := client.ListTunnelRoutesAll(ctx, AccountIdentifier(testAccountID), TunnelRoutesListParams{})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []TunnelRoute{{Network: "10.0.0.0/16", TunnelID: "f70ff985-a4ef-4643-bbbc-4a0ed4fc8415"}}, got)
+}
+
+func TestGetTunnelRoute(t *testing.T) {
+	setup()
+	defer teardown()
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": {
+			  "id": "e2ba5a4e-9a52-4c02-a5a6-1b1ad0ee0f6b",
+			  "network": "10.0.0.0/16",
+			  "tunnel_id": "f70ff985-a4ef-4643-bbbc-4a0ed4fc8415",
+			  "tunnel_name": "blog",
+			  "comment": "Example comment for this route",
+			  "created_at": "2021-01-25T18:22:34.317854Z",
+			  "deleted_at": null,
+			  "virtual_network_id": "9f322de4-5988-4945-b770-f1d6ac200f86"
+            }
+          }`)
+	}
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/e2ba5a4e-9a52-4c02-a5a6-1b1ad0ee0f6b", handler)
+
+	ts, _ := time.Parse(time.RFC3339Nano, "2021-01-25T18:22:34.317854Z")
+	want := TunnelRoute{
+		ID:               "e2ba5a4e-9a52-4c02-a5a6-1b1ad0ee0f6b",
+		Network:          "10.0.0.0/16",
+		TunnelID:         "f70ff985-a4ef-4643-bbbc-4a0ed4fc8415",
+		TunnelName:       "blog",
+		Comment:          "Example comment for this route",
+		CreatedAt:        &ts,
+		VirtualNetworkID: "9f322de4-5988-4945-b770-f1d6ac200f86",
+	}
 
+	_, err := client.GetTunnelRoute(context.Background(), AccountIdentifier(testAccountID), "")
+	assert.ErrorIs(t, err, ErrMissingTunnelRouteID)
+
+	got, err := client.GetTunnelRoute(context.Background(), AccountIdentifier(testAccountID), "e2ba5a4e-9a52-4c02-a5a6-1b1ad0ee0f6b")
 	if assert.NoError(t, err) {
 		assert.Equal(t, want, got)
 	}
@@ -217,6 +376,2915 @@ func TestDeleteTunnelRoute(t *testing.T) {
 	}
 
 	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/10.0.0.0/16", handler)
-	err := client.DeleteTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesDeleteParams{Network: "10.0.0.0/16", VirtualNetworkID: "9f322de4-5988-4945-b770-f1d6ac200f86"})
+	ts, _ := time.Parse(time.RFC3339Nano, "2021-01-25T18:22:34.317854Z")
+	want := TunnelRoute{
+		Network:          "ff01::/32",
+		TunnelID:         "f70ff985-a4ef-4643-bbbc-4a0ed4fc8415",
+		TunnelName:       "blog",
+		Comment:          "Example comment for this route",
+		CreatedAt:        &ts,
+		DeletedAt:        &ts,
+		VirtualNetworkID: "9f322de4-5988-4945-b770-f1d6ac200f86",
+	}
+
+	got, err := client.DeleteTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesDeleteParams{Network: "10.0.0.0/16", VirtualNetworkID: "9f322de4-5988-4945-b770-f1d6ac200f86"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, want, got)
+	}
+}
+
+func TestDeleteTunnelRoute_SoftDelete(t *testing.T) {
+	setup()
+	defer teardown()
+
+	deleted := false
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/10.0.0.0/16", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		switch r.Method {
+		case http.MethodDelete:
+			deleted = true
+			fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": {"id": "old", "network": "10.0.0.0/16", "deleted_at": "2021-01-25T18:22:34Z"}}`)
+		case http.MethodPost:
+			assert.True(t, deleted, "network should only be routed again once deleted")
+			fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": {"id": "new", "network": "10.0.0.0/16", "deleted_at": null}}`)
+		}
+	})
+
+	route, err := client.DeleteTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesDeleteParams{Network: "10.0.0.0/16"})
+	if assert.NoError(t, err) {
+		assert.True(t, route.IsDeleted())
+		assert.Equal(t, time.Date(2021, 1, 25, 18, 22, 34, 0, time.UTC), *route.DeletedAt)
+	}
+
+	// the network of a soft deleted route can be routed again immediately
+	route, err = client.CreateTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesCreateParams{Network: "10.0.0.0/16", TunnelID: testTunnelID})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "new", route.ID)
+		assert.False(t, route.IsDeleted())
+		assert.Nil(t, route.DeletedAt)
+	}
+}
+
+func TestDeleteTunnelRouteIfExists(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var status int
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/10.0.0.0/16", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method, "Expected method 'DELETE', got %s", r.Method)
+		w.Header().Set("content-type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprint(w, `{
+			"success": false,
+			"errors": [{"code": 1000, "message": "route not found"}],
+			"messages": [],
+			"result": null
+		  }`)
+	})
+
+	status = http.StatusNotFound
+	err := client.DeleteTunnelRouteIfExists(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesDeleteParams{Network: "10.0.0.0/16"})
+	assert.NoError(t, err)
+
+	status = http.StatusForbidden
+	err = client.DeleteTunnelRouteIfExists(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesDeleteParams{Network: "10.0.0.0/16"})
+	var authErr *AuthenticationError
+	assert.ErrorAs(t, err, &authErr)
+}
+
+func TestTunnelRoutes_PermissionError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	forbidden := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"success": false, "errors": [{"code": 10000, "message": "Authentication error"}], "messages": [], "result": null}`)
+	}
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", forbidden)
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/10.0.0.0/16", forbidden)
+
+	_, _, err := client.ListTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesListParams{})
+	assert.ErrorIs(t, err, ErrTunnelRoutePermission)
+	assert.True(t, IsAuthFailure(err))
+
+	_, err = client.CreateTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesCreateParams{Network: "10.0.0.0/16", TunnelID: testTunnelID})
+	assert.ErrorIs(t, err, ErrTunnelRoutePermission)
+	assert.Contains(t, err.Error(), "10.0.0.0/16")
+
+	var authErr *AuthenticationError
+	if assert.ErrorAs(t, err, &authErr) {
+		assert.True(t, authErr.InternalErrorCodeIs(10000))
+	}
+}
+
+func TestCreateTunnelRoute_InvalidNetwork(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Fail(t, "request should not have been sent for an invalid network")
+	})
+
+	for _, network := range []string{"10.0.0.0/33", "not-a-cidr", "10.0.0.1", "2001:db8::1", "2001:db8::/129"} {
+		_, err := client.CreateTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesCreateParams{TunnelID: testTunnelID, Network: network})
+		assert.ErrorIs(t, err, ErrInvalidNetworkCIDR, network)
+
+		_, err = client.UpdateTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesUpdateParams{TunnelID: testTunnelID, Network: network})
+		assert.ErrorIs(t, err, ErrInvalidNetworkCIDR, network)
+	}
+}
+
+func TestTunnelRoutes_VirtualNetworkID(t *testing.T) {
+	setup()
+	defer teardown()
+
+	vnetID := "9f322de4-5988-4945-b770-f1d6ac200f86"
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, vnetID, r.URL.Query().Get("virtual_network_id"))
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": []}`)
+	})
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/10.0.0.0/16", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if assert.NoError(t, err) {
+			assert.JSONEq(t, `{"tunnel_id": "`+testTunnelID+`", "virtual_network_id": "`+vnetID+`"}`, string(body))
+		}
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": {"network": "10.0.0.0/16", "virtual_network_id": "%s"}}`, vnetID)
+	})
+
+	_, _, err := client.ListTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesListParams{VirtualNetworkID: vnetID})
+	assert.NoError(t, err)
+
+	route, err := client.CreateTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesCreateParams{TunnelID: testTunnelID, Network: "10.0.0.0/16", VirtualNetworkID: vnetID})
+	if assert.NoError(t, err) {
+		assert.Equal(t, vnetID, route.VirtualNetworkID)
+	}
+}
+
+func TestBulkCreateTunnelRoutes(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method, "Expected method 'POST', got %s", r.Method)
+		w.Header().Set("content-type", "application/json")
+
+		network := strings.TrimPrefix(r.URL.Path, "/accounts/"+testAccountID+"/teamnet/routes/network/")
+		if network == "10.2.0.0/16" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"success": false, "errors": [{"code": 1000, "message": "bad route"}], "messages": [], "result": null}`)
+			return
+		}
+
+		fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": {"network": "%s", "tunnel_id": "%s"}}`, network, testTunnelID)
+	})
+
+	params := TunnelRoutesBulkCreateParams{
+		Routes: []TunnelRoutesCreateParams{
+			{TunnelID: testTunnelID, Network: "10.0.0.0/16"},
+			{TunnelID: testTunnelID, Network: "10.1.0.0/16"},
+			{TunnelID: testTunnelID, Network: "10.2.0.0/16"},
+			{TunnelID: testTunnelID, Network: "10.3.0.0/16"},
+		},
+		MaxInFlight: 2,
+	}
+
+	results, err := client.BulkCreateTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), params)
+
+	var bulkErr *TunnelRouteBulkError
+	if assert.ErrorAs(t, err, &bulkErr) {
+		assert.Len(t, bulkErr.Errors, 1)
+		assert.Equal(t, bulkErr.Errors, bulkErr.Unwrap())
+	}
+
+	var requestErr *RequestError
+	if assert.ErrorAs(t, err, &requestErr) {
+		assert.Equal(t, []int{1000}, requestErr.ErrorCodes())
+	}
+
+	if assert.Len(t, results, 4) {
+		for i, result := range results {
+			assert.Equal(t, params.Routes[i].Network, result.Network)
+			if result.Network == "10.2.0.0/16" {
+				assert.Error(t, result.Err)
+				continue
+			}
+			assert.NoError(t, result.Err)
+			assert.Equal(t, TunnelRoute{Network: result.Network, TunnelID: testTunnelID}, result.Route)
+		}
+	}
+}
+
+func TestCreateTunnelRoute_AlreadyExists(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/10.0.0.0/16", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method, "Expected method 'POST', got %s", r.Method)
+		w.Header().Set("content-type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{
+			"success": false,
+			"errors": [{"code": 1014, "message": "You already have a route defined for this exact IP subnet"}],
+			"messages": [],
+			"result": null
+		}`)
+	})
+
+	_, err := client.CreateTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesCreateParams{TunnelID: testTunnelID, Network: "10.0.0.0/16"})
+
+	assert.ErrorIs(t, err, ErrTunnelRouteExists)
+	assert.Contains(t, err.Error(), "10.0.0.0/16")
+
+	var reqErr *RequestError
+	if assert.ErrorAs(t, err, &reqErr) {
+		assert.True(t, reqErr.InternalErrorCodeIs(1014))
+	}
+}
+
+func TestListTunnelRoutes_TunnelIDs(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var wantQuery string
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, wantQuery, r.URL.RawQuery)
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": []}`)
+	})
+
+	testCases := map[string]struct {
+		params TunnelRoutesListParams
+		query  string
+	}{
+		"single tunnel ID": {
+			params: TunnelRoutesListParams{TunnelID: "a"},
+			query:  "per_page=100&tunnel_id=a",
+		},
+		"one of tunnel IDs": {
+			params: TunnelRoutesListParams{TunnelIDs: []string{"a"}},
+			query:  "per_page=100&tunnel_id=a",
+		},
+		"tunnel IDs take precedence": {
+			params: TunnelRoutesListParams{TunnelID: "c", TunnelIDs: []string{"a"}},
+			query:  "per_page=100&tunnel_id=a",
+		},
+		"empty tunnel IDs": {
+			params: TunnelRoutesListParams{TunnelIDs: []string{}},
+			query:  "per_page=100",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			wantQuery = tc.query
+			_, _, err := client.ListTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), tc.params)
+			assert.NoError(t, err)
+		})
+	}
+
+	_, _, err := client.ListTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesListParams{TunnelIDs: []string{"a", "b"}})
+	assert.ErrorIs(t, err, ErrMultipleTunnelIDs)
+}
+
+func TestListTunnelRoutesAll_TunnelIDs(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var requested []string
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		tunnelID := r.URL.Query().Get("tunnel_id")
+		assert.NotContains(t, tunnelID, ",")
+		requested = append(requested, tunnelID)
+
+		w.Header().Set("content-type", "application/json")
+		switch tunnelID {
+		case "a":
+			fmt.Fprint(w, `{
+				"success": true,
+				"errors": [],
+				"messages": [],
+				"result": [{"id": "1", "network": "10.0.0.0/16", "tunnel_id": "a"}, {"id": "2", "network": "10.1.0.0/16", "tunnel_id": "a"}],
+				"result_info": {"page": 1, "per_page": 100, "count": 2, "total_count": 2}
+			}`)
+		case "b":
+			// a deleted route of another tunnel whose network was reused
+			fmt.Fprint(w, `{
+				"success": true,
+				"errors": [],
+				"messages": [],
+				"result": [{"id": "3", "network": "10.2.0.0/16", "tunnel_id": "b"}, {"id": "4", "network": "10.0.0.0/16", "tunnel_id": "b", "deleted_at": "2021-01-01T00:00:00Z"}],
+				"result_info": {"page": 1, "per_page": 100, "count": 2, "total_count": 2}
+			}`)
+		default:
+			fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": []}`)
+		}
+	})
+
+	params := TunnelRoutesListParams{TunnelID: "ignored", TunnelIDs: []string{"a", "b", "a"}, Deleted: TunnelRoutesAll}
+
+	routes, resultInfo, err := client.ListTunnelRoutesAll(context.Background(), AccountIdentifier(testAccountID), params)
 	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, requested)
+	assert.Equal(t, 4, resultInfo.Count)
+	assert.Equal(t, 4, resultInfo.Total)
+
+	ids := []string{}
+	for _, route := range routes {
+		ids = append(ids, route.ID)
+	}
+	assert.Equal(t, []string{"1", "2", "3", "4"}, ids)
+
+	_, err = client.ListTunnelRoutesMap(context.Background(), AccountIdentifier(testAccountID), params)
+	assert.ErrorIs(t, err, ErrDuplicateTunnelRoute)
+}
+
+func FuzzTunnelRoutesListParams_Encode(f *testing.F) {
+	f.Add("a b", "10.0.0.0/16", "2001:db8::/32", "vnet", "key", "value")
+	f.Add("100% & more=#?", "ff01::%1/32", "", "", "a[]", "&=")
+	f.Add("", "", "", "", "", "")
+
+	f.Fuzz(func(t *testing.T, comment, subset, superset, vnet, extraKey, extraValue string) {
+		params := TunnelRoutesListParams{
+			Comment:          comment,
+			NetworkSubset:    subset,
+			NetworkSuperset:  superset,
+			VirtualNetworkID: vnet,
+			ExtraParams:      map[string]string{extraKey: extraValue},
+		}
+
+		values, err := url.ParseQuery(params.encode())
+		if err != nil {
+			t.Fatalf("encode produced an invalid query string: %s", err)
+		}
+
+		for key, want := range map[string]string{
+			"comment":            comment,
+			"network_subset":     subset,
+			"network_superset":   superset,
+			"virtual_network_id": vnet,
+		} {
+			if got := values.Get(key); got != want {
+				t.Errorf("%s: got %q, want %q", key, got, want)
+			}
+		}
+	})
+}
+
+func TestListTunnelRoutes_PerPage(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var perPage []string
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		perPage = append(perPage, r.URL.Query().Get("per_page"))
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": []}`)
+	})
+
+	for _, n := range []int{0, 1, 1000} {
+		_, _, err := client.ListTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesListParams{
+			PaginationOptions: PaginationOptions{PerPage: n},
+		})
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []string{"100", "1", "1000"}, perPage)
+
+	for _, n := range []int{-1, 1001} {
+		_, _, err := client.ListTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesListParams{
+			PaginationOptions: PaginationOptions{PerPage: n},
+		})
+		assert.ErrorIs(t, err, ErrInvalidPerPage)
+	}
+	assert.Len(t, perPage, 3)
+}
+
+func TestTunnelRoutesListParams_Encode(t *testing.T) {
+	testCases := map[string]struct {
+		params TunnelRoutesListParams
+		query  string
+	}{
+		"empty": {
+			params: TunnelRoutesListParams{},
+			query:  "",
+		},
+		"sorted keys": {
+			params: TunnelRoutesListParams{
+				VirtualNetworkID:  "vnet",
+				Comment:           "prod",
+				PaginationOptions: PaginationOptions{Page: 2, PerPage: 10},
+			},
+			query: "comment=prod&page=2&per_page=10&virtual_network_id=vnet",
+		},
+		"extra params": {
+			params: TunnelRoutesListParams{
+				Comment:     "prod",
+				ExtraParams: map[string]string{"z_experimental": "1", "a_experimental": "x"},
+			},
+			query: "a_experimental=x&comment=prod&z_experimental=1",
+		},
+		"tunnel types": {
+			params: TunnelRoutesListParams{TunnelTypes: []string{TunnelRouteTypeCloudflared, TunnelRouteTypeWarpConnector}},
+			query:  "tun_types=cfd_tunnel%2Cwarp_connector",
+		},
+		"only active": {
+			params: TunnelRoutesListParams{Deleted: TunnelRoutesActive},
+			query:  "is_deleted=false",
+		},
+		"only deleted": {
+			params: TunnelRoutesListParams{Deleted: TunnelRoutesDeleted},
+			query:  "is_deleted=true",
+		},
+		"deleted and active": {
+			params: TunnelRoutesListParams{Deleted: TunnelRoutesAll, IsDeleted: BoolPtr(true)},
+			query:  "",
+		},
+		"deleted takes precedence": {
+			params: TunnelRoutesListParams{Deleted: TunnelRoutesActive, IsDeleted: BoolPtr(true)},
+			query:  "is_deleted=false",
+		},
+		"extra params do not replace fields": {
+			params: TunnelRoutesListParams{
+				Comment:     "prod",
+				ExtraParams: map[string]string{"comment": "staging"},
+			},
+			query: "comment=prod",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.query, tc.params.encode())
+		})
+	}
+}
+
+func TestRestoreTunnelRoute(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		assert.Equal(t, "true", r.URL.Query().Get("is_deleted"))
+		assert.Equal(t, "10.0.0.0/16", r.URL.Query().Get("network_subset"))
+		assert.Equal(t, "10.0.0.0/16", r.URL.Query().Get("network_superset"))
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": [
+			  {
+			    "network": "10.0.0.0/16",
+			    "tunnel_id": "old",
+			    "comment": "older",
+			    "deleted_at": "2021-01-25T18:22:34.317854Z",
+			    "virtual_network_id": "9f322de4-5988-4945-b770-f1d6ac200f86"
+			  },
+			  {
+			    "network": "10.0.0.0/16",
+			    "tunnel_id": "f70ff985-a4ef-4643-bbbc-4a0ed4fc8415",
+			    "comment": "newer",
+			    "deleted_at": "2022-01-25T18:22:34.317854Z",
+			    "virtual_network_id": "9f322de4-5988-4945-b770-f1d6ac200f86"
+			  }
+			]
+		  }`)
+	})
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/10.0.0.0/16", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method, "Expected method 'POST', got %s", r.Method)
+		body, err := io.ReadAll(r.Body)
+		if assert.NoError(t, err) {
+			assert.JSONEq(t, `{"tunnel_id": "f70ff985-a4ef-4643-bbbc-4a0ed4fc8415", "comment": "newer", "virtual_network_id": "9f322de4-5988-4945-b770-f1d6ac200f86"}`, string(body))
+		}
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": {
+			  "network": "10.0.0.0/16",
+			  "tunnel_id": "f70ff985-a4ef-4643-bbbc-4a0ed4fc8415",
+			  "comment": "newer",
+			  "deleted_at": null,
+			  "virtual_network_id": "9f322de4-5988-4945-b770-f1d6ac200f86"
+			}
+		  }`)
+	})
+
+	got, err := client.RestoreTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesRestoreParams{Network: "10.0.0.0/16"})
+	if assert.NoError(t, err) {
+		assert.Nil(t, got.DeletedAt)
+		assert.Equal(t, "newer", got.Comment)
+	}
+}
+
+func TestRestoreTunnelRoute_NotFound(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": []}`)
+	})
+
+	_, err := client.RestoreTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesRestoreParams{Network: "10.0.0.0/16"})
+	assert.ErrorIs(t, err, ErrTunnelRouteNotFound)
+}
+
+func TestCreateTunnelRouteWithResponse(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/10.0.0.0/16", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method, "Expected method 'POST', got %s", r.Method)
+		w.Header().Set("content-type", "application/json")
+		w.Header().Set("CF-RateLimit-Remaining", "42")
+		w.Header().Set("cf-ray", "7e2b3c4d5e6f7a8b-SJC")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": {"network": "10.0.0.0/16", "tunnel_id": "f70ff985-a4ef-4643-bbbc-4a0ed4fc8415"}}`)
+	})
+
+	route, res, err := client.CreateTunnelRouteWithResponse(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesCreateParams{TunnelID: testTunnelID, Network: "10.0.0.0/16"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "10.0.0.0/16", route.Network)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "42", res.Headers.Get("CF-RateLimit-Remaining"))
+		assert.Equal(t, "7e2b3c4d5e6f7a8b-SJC", res.Headers.Get("cf-ray"))
+	}
+}
+
+func TestUpdateTunnelRouteComment(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var wantBody, comment string
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/10.0.0.0/16", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method, "Expected method 'PATCH', got %s", r.Method)
+		body, err := io.ReadAll(r.Body)
+		if assert.NoError(t, err) {
+			assert.NotContains(t, string(body), "tunnel_id")
+			assert.JSONEq(t, wantBody, string(body))
+		}
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": {"network": "10.0.0.0/16", "tunnel_id": "f70ff985-a4ef-4643-bbbc-4a0ed4fc8415", "comment": "%s"}}`, comment)
+	})
+
+	wantBody, comment = `{"network": "10.0.0.0/16", "comment": "new comment"}`, "new comment"
+	route, err := client.UpdateTunnelRouteComment(context.Background(), AccountIdentifier(testAccountID), UpdateTunnelRouteCommentParams{Network: "10.0.0.0/16", Comment: "new comment"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "new comment", route.Comment)
+		assert.Equal(t, "f70ff985-a4ef-4643-bbbc-4a0ed4fc8415", route.TunnelID)
+	}
+
+	wantBody, comment = `{"network": "10.0.0.0/16", "comment": ""}`, ""
+	route, err = client.UpdateTunnelRouteComment(context.Background(), AccountIdentifier(testAccountID), UpdateTunnelRouteCommentParams{Network: "10.0.0.0/16"})
+	if assert.NoError(t, err) {
+		assert.Empty(t, route.Comment)
+	}
+
+	_, err = client.UpdateTunnelRouteComment(context.Background(), AccountIdentifier(testAccountID), UpdateTunnelRouteCommentParams{Network: "10.0.0.0/16", Comment: strings.Repeat("a", TunnelRouteCommentMaxLength+1)})
+	assert.ErrorIs(t, err, ErrCommentTooLong)
+}
+
+func TestTunnelRoute_JSONRoundTrip(t *testing.T) {
+	loc := time.FixedZone("AEST", 10*60*60)
+	createdAt := time.Date(2021, 1, 26, 4, 22, 34, 317854000, loc)
+
+	route := TunnelRoute{
+		ID:               "e2ba5a4e-9a52-4c02-a5a6-1b1ad0ee0f6b",
+		Network:          "10.0.0.0/16",
+		TunnelID:         "f70ff985-a4ef-4643-bbbc-4a0ed4fc8415",
+		TunnelName:       "blog",
+		Comment:          "Example comment for this route",
+		CreatedAt:        &createdAt,
+		VirtualNetworkID: "9f322de4-5988-4945-b770-f1d6ac200f86",
+	}
+
+	b, err := json.Marshal(route)
+	if assert.NoError(t, err) {
+		assert.JSONEq(t, `{
+			"id": "e2ba5a4e-9a52-4c02-a5a6-1b1ad0ee0f6b",
+			"network": "10.0.0.0/16",
+			"tunnel_id": "f70ff985-a4ef-4643-bbbc-4a0ed4fc8415",
+			"tunnel_name": "blog",
+			"comment": "Example comment for this route",
+			"created_at": "2021-01-26T04:22:34.317854+10:00",
+			"deleted_at": null,
+			"virtual_network_id": "9f322de4-5988-4945-b770-f1d6ac200f86"
+		}`, string(b))
+	}
+
+	var got TunnelRoute
+	if assert.NoError(t, json.Unmarshal(b, &got)) {
+		assert.True(t, createdAt.Equal(*got.CreatedAt))
+		assert.Nil(t, got.DeletedAt)
+
+		got.CreatedAt = route.CreatedAt
+		assert.Equal(t, route, got)
+	}
+}
+
+func TestGetTunnelRouteForIP_Validation(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/ip/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Fail(t, "request should not have been sent for an invalid IP")
+	})
+
+	_, err := client.GetTunnelRouteForIP(context.Background(), AccountIdentifier(""), TunnelRoutesForIPParams{Network: "10.1.0.137"})
+	assert.ErrorIs(t, err, ErrMissingAccountID)
+
+	_, err = client.GetTunnelRouteForIP(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesForIPParams{})
+	assert.ErrorIs(t, err, ErrMissingNetwork)
+
+	for _, ip := range []string{"10.1.0.0/16", "2001:db8::/32", "not-an-ip", "10.1.0.256", "10.1.0.137?foo=bar"} {
+		_, err = client.GetTunnelRouteForIP(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesForIPParams{Network: ip})
+		assert.ErrorIs(t, err, ErrInvalidNetworkValue, ip)
+	}
+}
+
+func TestGetTunnelRouteForIP_Normalised(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var wantPath string
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/ip/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		assert.Equal(t, wantPath, r.URL.EscapedPath())
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": {"network": "10.0.0.0/8"}}`)
+	})
+
+	testCases := map[string]struct {
+		ip   string
+		want string
+	}{
+		"upper case IPv6":    {ip: "2001:DB8::1", want: "2001:db8::1"},
+		"zero padded IPv6":   {ip: "2001:0db8:0000:0000:0000:0000:0000:0001", want: "2001:db8::1"},
+		"IPv4 mapped IPv6":   {ip: "::ffff:10.1.0.137", want: "10.1.0.137"},
+		"IPv4 is unchanged":  {ip: "10.1.0.137", want: "10.1.0.137"},
+		"upper case mapped":  {ip: "::FFFF:10.1.0.137", want: "10.1.0.137"},
+		"compressed is kept": {ip: "fe80::1", want: "fe80::1"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			wantPath = "/accounts/" + testAccountID + "/teamnet/routes/ip/" + tc.want
+			_, err := client.GetTunnelRouteForIP(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesForIPParams{Network: tc.ip})
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestStreamTunnelRoutes(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		w.Header().Set("content-type", "application/json")
+		page := r.URL.Query().Get("page")
+		fmt.Fprintf(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": [{"network": "10.%s.0.0/16"}],
+			"result_info": {"page": %s, "per_page": 1, "count": 1, "total_count": 3, "total_pages": 3}
+		  }`, page, page)
+	})
+
+	routes, errc := client.StreamTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesListParams{PaginationOptions: PaginationOptions{PerPage: 1}})
+
+	var got []string
+	for route := range routes {
+		got = append(got, route.Network)
+	}
+
+	assert.NoError(t, <-errc)
+	assert.Equal(t, []string{"10.1.0.0/16", "10.2.0.0/16", "10.3.0.0/16"}, got)
+}
+
+func TestStreamTunnelRoutes_Errors(t *testing.T) {
+	setup()
+	defer teardown()
+
+	routes, errc := client.StreamTunnelRoutes(context.Background(), AccountIdentifier(""), TunnelRoutesListParams{})
+	_, ok := <-routes
+	assert.False(t, ok)
+	assert.ErrorIs(t, <-errc, ErrMissingAccountID)
+	_, ok = <-errc
+	assert.False(t, ok)
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "1", r.URL.Query().Get("page"))
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": [{"network": "10.0.0.0/16"}, {"network": "10.1.0.0/16"}],
+			"result_info": {"page": 1, "per_page": 2, "count": 2, "total_count": 4, "total_pages": 2}
+		  }`)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	routes, errc = client.StreamTunnelRoutes(ctx, AccountIdentifier(testAccountID), TunnelRoutesListParams{})
+
+	<-routes
+	cancel()
+
+	for range routes {
+	}
+	assert.ErrorIs(t, <-errc, context.Canceled)
+}
+
+func TestForEachTunnelRoute(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests := 0
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		requests++
+		w.Header().Set("content-type", "application/json")
+		page := r.URL.Query().Get("page")
+		fmt.Fprintf(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": [{"network": "10.%s.0.0/16"}],
+			"result_info": {"page": %s, "per_page": 1, "count": 1, "total_count": 3, "total_pages": 3}
+		  }`, page, page)
+	})
+
+	var got []string
+	err := client.ForEachTunnelRoute(context.Background(), AccountIdentifier(testAccountID), ForEachTunnelRouteParams{Fn: func(route TunnelRoute) error {
+		got = append(got, route.Network)
+		return nil
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.1.0.0/16", "10.2.0.0/16", "10.3.0.0/16"}, got)
+	assert.Equal(t, 3, requests)
+
+	errStop := errors.New("stop")
+	requests = 0
+	got = nil
+	err = client.ForEachTunnelRoute(context.Background(), AccountIdentifier(testAccountID), ForEachTunnelRouteParams{Fn: func(route TunnelRoute) error {
+		got = append(got, route.Network)
+		if len(got) == 2 {
+			return errStop
+		}
+		return nil
+	}})
+	assert.Equal(t, errStop, err)
+	assert.Equal(t, []string{"10.1.0.0/16", "10.2.0.0/16"}, got)
+	assert.Equal(t, 2, requests)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err = client.ForEachTunnelRoute(ctx, AccountIdentifier(testAccountID), ForEachTunnelRouteParams{Fn: func(route TunnelRoute) error {
+		cancel()
+		return nil
+	}})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	err = client.ForEachTunnelRoute(context.Background(), AccountIdentifier(""), ForEachTunnelRouteParams{Fn: func(TunnelRoute) error { return nil }})
+	assert.ErrorIs(t, err, ErrMissingAccountID)
+
+	err = client.ForEachTunnelRoute(context.Background(), AccountIdentifier(testAccountID), ForEachTunnelRouteParams{})
+	assert.ErrorIs(t, err, ErrMissingForEachFunc)
+}
+
+func TestListTunnelRoutesFiltered_CreatedAt(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		assert.Equal(t, "", r.URL.Query().Get("created_after"))
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": [
+			  {"network": "10.0.0.0/16", "created_at": "2021-01-01T00:00:00Z"},
+			  {"network": "10.1.0.0/16", "created_at": "2021-02-01T00:00:00Z"},
+			  {"network": "10.2.0.0/16", "created_at": "2021-03-01T00:00:00Z"},
+			  {"network": "10.3.0.0/16"}
+			]
+		  }`)
+	})
+
+	after := time.Date(2021, 1, 15, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2021, 2, 15, 0, 0, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		filter TunnelRoutesFilter
+		want   []string
+	}{
+		"no filter": {
+			filter: TunnelRoutesFilter{},
+			want:   []string{"10.0.0.0/16", "10.1.0.0/16", "10.2.0.0/16", "10.3.0.0/16"},
+		},
+		"created after": {
+			filter: TunnelRoutesFilter{CreatedAfter: &after},
+			want:   []string{"10.1.0.0/16", "10.2.0.0/16"},
+		},
+		"created before": {
+			filter: TunnelRoutesFilter{CreatedBefore: &before},
+			want:   []string{"10.0.0.0/16", "10.1.0.0/16"},
+		},
+		"created between": {
+			filter: TunnelRoutesFilter{CreatedAfter: &after, CreatedBefore: &before},
+			want:   []string{"10.1.0.0/16"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			routes, err := client.ListTunnelRoutesFiltered(context.Background(), AccountIdentifier(testAccountID), ListTunnelRoutesFilteredParams{Filter: tc.filter})
+			if assert.NoError(t, err) {
+				got := []string{}
+				for _, route := range routes {
+					got = append(got, route.Network)
+				}
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestListTunnelRoutesFiltered_Comment(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var wantComment string
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		assert.Equal(t, wantComment, r.URL.Query().Get("comment"))
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": [
+			  {"network": "10.0.0.0/16", "comment": "prod-web"},
+			  {"network": "10.1.0.0/16", "comment": "prod-db"},
+			  {"network": "10.2.0.0/16", "comment": "staging-prod"},
+			  {"network": "10.3.0.0/16", "comment": "prod"}
+			]
+		  }`)
+	})
+
+	testCases := map[string]struct {
+		filter      TunnelRoutesFilter
+		wantComment string
+		want        []string
+	}{
+		"exact": {
+			filter:      TunnelRoutesFilter{Comment: "prod"},
+			wantComment: "prod",
+			want:        []string{"10.3.0.0/16"},
+		},
+		"prefix": {
+			filter: TunnelRoutesFilter{Comment: "prod-", CommentMatch: TunnelRouteCommentPrefix},
+			want:   []string{"10.0.0.0/16", "10.1.0.0/16"},
+		},
+		"contains": {
+			filter: TunnelRoutesFilter{Comment: "prod", CommentMatch: TunnelRouteCommentContains},
+			want:   []string{"10.0.0.0/16", "10.1.0.0/16", "10.2.0.0/16", "10.3.0.0/16"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			wantComment = tc.wantComment
+			routes, err := client.ListTunnelRoutesFiltered(context.Background(), AccountIdentifier(testAccountID), ListTunnelRoutesFilteredParams{Filter: tc.filter})
+			if assert.NoError(t, err) {
+				got := []string{}
+				for _, route := range routes {
+					got = append(got, route.Network)
+				}
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDiffTunnelRoutes(t *testing.T) {
+	created := time.Date(2021, 1, 25, 18, 22, 34, 0, time.UTC)
+
+	current := []TunnelRoute{
+		{ID: "1", Network: "10.0.0.0/16", TunnelID: "a", Comment: "same", CreatedAt: &created},
+		{ID: "2", Network: "10.1.0.0/16", TunnelID: "a", Comment: "old"},
+		{ID: "3", Network: "10.2.0.0/16", TunnelID: "a"},
+		{ID: "4", Network: "10.3.0.0/16", TunnelID: "a"},
+		{ID: "5", Network: "10.4.0.0/16", TunnelID: "a", VirtualNetworkID: "vnet-1"},
+	}
+	desired := []TunnelRoute{
+		{Network: "10.0.0.0/16", TunnelID: "a", Comment: "same"},
+		{Network: "10.1.0.0/16", TunnelID: "a", Comment: "new"},
+		{Network: "10.2.0.0/16", TunnelID: "b"},
+		{Network: "10.4.0.0/16", TunnelID: "a", VirtualNetworkID: "vnet-2"},
+		{Network: "10.5.0.0/16", TunnelID: "a"},
+	}
+
+	toCreate, toUpdate, toDelete := DiffTunnelRoutes(desired, current)
+
+	assert.Equal(t, []TunnelRoute{
+		{Network: "10.4.0.0/16", TunnelID: "a", VirtualNetworkID: "vnet-2"},
+		{Network: "10.5.0.0/16", TunnelID: "a"},
+	}, toCreate)
+	assert.Equal(t, []TunnelRoute{
+		{ID: "2", Network: "10.1.0.0/16", TunnelID: "a", Comment: "new"},
+		{ID: "3", Network: "10.2.0.0/16", TunnelID: "b"},
+	}, toUpdate)
+	assert.Equal(t, []TunnelRoute{
+		{ID: "4", Network: "10.3.0.0/16", TunnelID: "a"},
+		{ID: "5", Network: "10.4.0.0/16", TunnelID: "a", VirtualNetworkID: "vnet-1"},
+	}, toDelete)
+}
+
+func TestListTunnelRoutes_Timeout(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+
+	_, _, err := client.ListTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesListParams{Timeout: 10 * time.Millisecond})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, _, err = client.ListTunnelRoutesAll(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesListParams{Timeout: 10 * time.Millisecond})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestListTunnelRoutesAll_Cursor(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		w.Header().Set("content-type", "application/json")
+
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			assert.Equal(t, "1", r.URL.Query().Get("page"))
+			fmt.Fprint(w, `{
+				"success": true,
+				"errors": [],
+				"messages": [],
+				"result": [{"network": "10.0.0.0/16"}],
+				"result_info": {"page": 1, "per_page": 1, "cursor": "abc"}
+			}`)
+		case "abc":
+			assert.Equal(t, "", r.URL.Query().Get("page"))
+			fmt.Fprint(w, `{
+				"success": true,
+				"errors": [],
+				"messages": [],
+				"result": [{"network": "10.1.0.0/16"}],
+				"result_info": {"per_page": 1, "cursors": {"after": "def"}}
+			}`)
+		case "def":
+			fmt.Fprint(w, `{
+				"success": true,
+				"errors": [],
+				"messages": [],
+				"result": [{"network": "10.2.0.0/16"}],
+				"result_info": {"per_page": 1}
+			}`)
+		default:
+			assert.Fail(t, "unexpected cursor", r.URL.Query().Get("cursor"))
+		}
+	})
+
+	routes, _, err := client.ListTunnelRoutesAll(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesListParams{})
+	if assert.NoError(t, err) {
+		assert.Len(t, routes, 3)
+		assert.Equal(t, "10.2.0.0/16", routes[2].Network)
+	}
+}
+
+func TestPlanTunnelRoute(t *testing.T) {
+	setup()
+	defer teardown()
+
+	req, err := client.PlanCreateTunnelRoute(AccountIdentifier(testAccountID), TunnelRoutesCreateParams{
+		Network:  "10.0.0.0/16",
+		TunnelID: testTunnelID,
+		Comment:  "example",
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, client.BaseURL+"/accounts/"+testAccountID+"/teamnet/routes/network/10.0.0.0%2F16", req.URL)
+		assert.JSONEq(t, `{"tunnel_id": "`+testTunnelID+`", "comment": "example"}`, string(req.Body))
+	}
+
+	req, err = client.PlanUpdateTunnelRoute(AccountIdentifier(testAccountID), TunnelRoutesUpdateParams{
+		Network: "10.0.0.0/16",
+		Comment: "updated",
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.MethodPatch, req.Method)
+		assert.Equal(t, client.BaseURL+"/accounts/"+testAccountID+"/teamnet/routes/network/10.0.0.0%2F16", req.URL)
+		assert.JSONEq(t, `{"network": "10.0.0.0/16", "comment": "updated"}`, string(req.Body))
+	}
+
+	_, err = client.PlanCreateTunnelRoute(AccountIdentifier(""), TunnelRoutesCreateParams{Network: "10.0.0.0/16"})
+	assert.ErrorIs(t, err, ErrMissingAccountID)
+
+	_, err = client.PlanCreateTunnelRoute(AccountIdentifier(testAccountID), TunnelRoutesCreateParams{Network: "10.0.0.1"})
+	assert.ErrorIs(t, err, ErrInvalidNetworkCIDR)
+}
+
+func TestTunnelRoutes_UnsuccessfulResponse(t *testing.T) {
+	setup()
+	defer teardown()
+
+	body := `{
+		"success": false,
+		"errors": [{"code": 1001, "message": "something went wrong"}],
+		"messages": [],
+		"result": null
+	}`
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, body)
+	}
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", handler)
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/10.0.0.0/16", handler)
+
+	assertRequestError := func(t *testing.T, err error) {
+		var reqErr *RequestError
+		if assert.ErrorAs(t, err, &reqErr) {
+			assert.Equal(t, []int{1001}, reqErr.ErrorCodes())
+			assert.Equal(t, []string{"something went wrong"}, reqErr.ErrorMessages())
+			assert.True(t, reqErr.InternalErrorCodeIs(1001))
+		}
+	}
+
+	_, _, err := client.ListTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesListParams{})
+	assertRequestError(t, err)
+
+	_, err = client.CreateTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesCreateParams{Network: "10.0.0.0/16", TunnelID: testTunnelID})
+	assertRequestError(t, err)
+
+	_, err = client.UpdateTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesUpdateParams{Network: "10.0.0.0/16", TunnelID: testTunnelID})
+	assertRequestError(t, err)
+
+	_, err = client.DeleteTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesDeleteParams{Network: "10.0.0.0/16"})
+	assertRequestError(t, err)
+}
+
+func TestReplaceTunnelRoutes(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/virtual_networks", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.URL.Query().Get("is_default"))
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": [{"id": "default-vnet", "is_default_network": true}]}`)
+	})
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		assert.Equal(t, testTunnelID, r.URL.Query().Get("tunnel_id"))
+		assert.Equal(t, "false", r.URL.Query().Get("is_deleted"))
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": [
+			  {"id": "1", "network": "10.0.0.0/16", "tunnel_id": "%[1]s", "comment": "keep", "virtual_network_id": "default-vnet"},
+			  {"id": "2", "network": "10.1.0.0/16", "tunnel_id": "%[1]s", "comment": "old", "virtual_network_id": "default-vnet"},
+			  {"id": "3", "network": "10.2.0.0/16", "tunnel_id": "%[1]s", "virtual_network_id": "default-vnet"},
+			  {"id": "4", "network": "10.4.0.0/16", "tunnel_id": "%[1]s", "comment": "unchanged", "virtual_network_id": "default-vnet"},
+			  {"id": "5", "network": "10.4.0.0/16", "tunnel_id": "%[1]s", "virtual_network_id": "other-vnet"}
+			]
+		  }`, testTunnelID)
+	})
+
+	var mu sync.Mutex
+	calls := map[string]string{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls[r.URL.EscapedPath()] = r.Method
+		mu.Unlock()
+
+		body, _ := io.ReadAll(r.Body)
+		if len(body) > 0 {
+			assert.Contains(t, string(body), testTunnelID)
+		}
+
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": {"network": "%s"}}`, strings.TrimPrefix(r.URL.Path, "/accounts/"+testAccountID+"/teamnet/routes/network/"))
+	}
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/", handler)
+
+	result, err := client.ReplaceTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesReplaceParams{
+		TunnelID: testTunnelID,
+		Routes: []TunnelRoutesCreateParams{
+			{Network: "10.0.0.0/16", Comment: "keep"},
+			{Network: "10.1.0.0/16", Comment: "new"},
+			{Network: "10.3.0.0/16", TunnelID: "ignored"},
+			{Network: "10.4.0.0/16"},
+			{Network: "10.4.0.0/16", VirtualNetworkID: "other-vnet"},
+		},
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []TunnelRoute{{Network: "10.3.0.0/16"}}, result.Created)
+		assert.Equal(t, []TunnelRoute{{Network: "10.1.0.0/16"}}, result.Updated)
+		assert.Equal(t, []TunnelRoute{{ID: "3", Network: "10.2.0.0/16", TunnelID: testTunnelID, VirtualNetworkID: "default-vnet"}}, result.Deleted)
+	}
+
+	prefix := "/accounts/" + testAccountID + "/teamnet/routes/network/"
+	assert.Equal(t, map[string]string{
+		prefix + "10.3.0.0%2F16": http.MethodPost,
+		prefix + "10.1.0.0%2F16": http.MethodPatch,
+		prefix + "10.2.0.0%2F16": http.MethodDelete,
+	}, calls)
+
+	_, err = client.ReplaceTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesReplaceParams{})
+	assert.ErrorIs(t, err, ErrMissingTunnelID)
+}
+
+func TestReplaceTunnelRoutes_DefaultVirtualNetwork(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/virtual_networks", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.URL.Query().Get("is_default"))
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": [{"id": "default-vnet", "is_default_network": true}]}`)
+	})
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": [
+			  {"id": "1", "network": "10.0.0.0/16", "tunnel_id": "%[1]s", "comment": "a", "virtual_network_id": "default-vnet"},
+			  {"id": "2", "network": "10.1.0.0/16", "tunnel_id": "%[1]s", "virtual_network_id": "default-vnet"}
+			]
+		  }`, testTunnelID)
+	})
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+	})
+
+	// the routes are already in place, so repeated calls change nothing
+	for i := 0; i < 2; i++ {
+		result, err := client.ReplaceTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesReplaceParams{
+			TunnelID: testTunnelID,
+			Routes: []TunnelRoutesCreateParams{
+				{Network: "10.0.0.0/16", Comment: "a"},
+				{Network: "10.1.0.0/16"},
+			},
+		})
+		if assert.NoError(t, err) {
+			assert.Empty(t, result.Created)
+			assert.Empty(t, result.Updated)
+			assert.Empty(t, result.Deleted)
+		}
+	}
+}
+
+func TestReplaceTunnelRoutes_MaxDeletes(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/virtual_networks", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.URL.Query().Get("is_default"))
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": [{"id": "default-vnet", "is_default_network": true}]}`)
+	})
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": [
+			  {"network": "10.0.0.0/16", "tunnel_id": "%[1]s", "virtual_network_id": "default-vnet"},
+			  {"network": "10.1.0.0/16", "tunnel_id": "%[1]s", "virtual_network_id": "default-vnet"},
+			  {"network": "10.2.0.0/16", "tunnel_id": "%[1]s", "virtual_network_id": "default-vnet"},
+			  {"network": "10.3.0.0/16", "tunnel_id": "%[1]s", "virtual_network_id": "default-vnet"}
+			]
+		  }`, testTunnelID)
+	})
+
+	var mu sync.Mutex
+	deletes := 0
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method, "Expected method 'DELETE', got %s", r.Method)
+		mu.Lock()
+		deletes++
+		mu.Unlock()
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": {}}`)
+	})
+
+	// keeping a single route deletes 3 of the 4 routes, 75%
+	testCases := map[string]struct {
+		maxDeletes       int
+		maxDeletePercent float64
+		force            bool
+		wantErr          bool
+	}{
+		"no limit":                {},
+		"at max deletes":          {maxDeletes: 3},
+		"over max deletes":        {maxDeletes: 2, wantErr: true},
+		"at max percent":          {maxDeletePercent: 75},
+		"over max percent":        {maxDeletePercent: 74.9, wantErr: true},
+		"either limit exceeded":   {maxDeletes: 3, maxDeletePercent: 50, wantErr: true},
+		"forced over max deletes": {maxDeletes: 1, maxDeletePercent: 10, force: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			deletes = 0
+
+			result, err := client.ReplaceTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesReplaceParams{
+				TunnelID:         testTunnelID,
+				Routes:           []TunnelRoutesCreateParams{{Network: "10.0.0.0/16"}},
+				MaxDeletes:       tc.maxDeletes,
+				MaxDeletePercent: tc.maxDeletePercent,
+				Force:            tc.force,
+			})
+
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrTooManyDeletions)
+				assert.Empty(t, result.Deleted)
+				assert.Equal(t, 0, deletes)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Len(t, result.Deleted, 3)
+			assert.Equal(t, 3, deletes)
+		})
+	}
+}
+
+func TestTunnelRoutesWithinNetwork(t *testing.T) {
+	routes := []TunnelRoute{
+		{Network: "10.0.0.0/8"},
+		{Network: "10.1.0.0/16"},
+		{Network: "10.1.2.0/24"},
+		{Network: "192.168.0.0/16"},
+		{Network: "::ffff:10.1.3.0/120"},
+		{Network: "2001:db8::/32"},
+		{Network: "2001:db8:1::/48"},
+		{Network: "not-a-network"},
+	}
+
+	testCases := map[string]struct {
+		supernet string
+		want     []string
+	}{
+		"IPv4": {
+			supernet: "10.1.0.0/16",
+			want:     []string{"10.1.0.0/16", "10.1.2.0/24", "::ffff:10.1.3.0/120"},
+		},
+		"IPv4 host bits set": {
+			supernet: "10.1.9.9/16",
+			want:     []string{"10.1.0.0/16", "10.1.2.0/24", "::ffff:10.1.3.0/120"},
+		},
+		"IPv6": {
+			supernet: "2001:db8::/32",
+			want:     []string{"2001:db8::/32", "2001:db8:1::/48"},
+		},
+		"nothing within": {
+			supernet: "172.16.0.0/12",
+			want:     []string{},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, err := TunnelRoutesWithinNetwork(routes, tc.supernet)
+			assert.ErrorIs(t, err, ErrInvalidNetworkCIDR)
+			assert.Contains(t, err.Error(), `"not-a-network"`)
+
+			networks := []string{}
+			for _, route := range got {
+				networks = append(networks, route.Network)
+			}
+			assert.Equal(t, tc.want, networks)
+		})
+	}
+
+	_, err := TunnelRoutesWithinNetwork(routes, "10.0.0.1")
+	assert.ErrorIs(t, err, ErrInvalidNetworkCIDR)
+
+	got, err := TunnelRoutesWithinNetwork(routes[:3], "10.0.0.0/8")
+	assert.NoError(t, err)
+	assert.Len(t, got, 3)
+}
+
+func TestActiveTunnelRoutes(t *testing.T) {
+	deletedAt := time.Date(2021, 1, 25, 18, 22, 34, 0, time.UTC)
+	routes := []TunnelRoute{
+		{ID: "a", Network: "10.0.0.0/16"},
+		{ID: "b", Network: "10.1.0.0/16", DeletedAt: &deletedAt},
+		{ID: "c", Network: "10.2.0.0/16"},
+		{ID: "d", Network: "10.3.0.0/16", DeletedAt: &deletedAt},
+	}
+
+	assert.Equal(t, []TunnelRoute{routes[0], routes[2]}, ActiveTunnelRoutes(routes))
+	assert.Empty(t, ActiveTunnelRoutes(routes[1:2]))
+	assert.Empty(t, ActiveTunnelRoutes(nil))
+}
+
+func TestFindOverlappingTunnelRoutes(t *testing.T) {
+	routes := []TunnelRoute{
+		{ID: "1", Network: "10.0.0.0/8"},
+		{ID: "2", Network: "10.1.0.0/16", VirtualNetworkID: "vnet-1"},
+		{ID: "3", Network: "10.1.2.0/24", VirtualNetworkID: "vnet-2"},
+		{ID: "4", Network: "192.168.0.0/16", VirtualNetworkID: "vnet-1"},
+		{ID: "5", Network: "2001:db8::/32"},
+	}
+
+	testCases := map[string]struct {
+		candidate TunnelRoute
+		want      []string
+	}{
+		"contained within":    {candidate: TunnelRoute{Network: "10.1.2.128/25", VirtualNetworkID: "vnet-1"}, want: []string{"1", "2"}},
+		"contains":            {candidate: TunnelRoute{Network: "192.0.0.0/8", VirtualNetworkID: "vnet-1"}, want: []string{"4"}},
+		"other vnet":          {candidate: TunnelRoute{Network: "192.168.1.0/24", VirtualNetworkID: "vnet-2"}, want: nil},
+		"default vnet":        {candidate: TunnelRoute{Network: "10.1.0.0/16"}, want: []string{"1", "2", "3"}},
+		"no overlap":          {candidate: TunnelRoute{Network: "172.16.0.0/12"}, want: nil},
+		"IPv6":                {candidate: TunnelRoute{Network: "2001:db8:1::/48"}, want: []string{"5"}},
+		"IPv4 and IPv6 apart": {candidate: TunnelRoute{Network: "::/0"}, want: []string{"5"}},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			overlapping, err := FindOverlappingTunnelRoutes(routes, tc.candidate)
+			if assert.NoError(t, err) {
+				var got []string
+				for _, route := range overlapping {
+					got = append(got, route.ID)
+				}
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+
+	_, err := FindOverlappingTunnelRoutes(routes, TunnelRoute{Network: "10.0.0.1"})
+	assert.ErrorIs(t, err, ErrInvalidNetworkCIDR)
+}
+
+func TestCreateTunnelRoute_RequestID(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/10.0.0.0/16", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "req-123", r.Header.Get("X-Request-ID"))
+		w.Header().Set("content-type", "application/json")
+		w.Header().Set("cf-ray", "7d1e4c5b9f8a1234-LHR")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": {"network": "10.0.0.0/16"}}`)
+	})
+
+	ctx := WithRequestID(context.Background(), "req-123")
+	_, res, err := client.CreateTunnelRouteWithResponse(ctx, AccountIdentifier(testAccountID), TunnelRoutesCreateParams{Network: "10.0.0.0/16", TunnelID: testTunnelID})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "7d1e4c5b9f8a1234-LHR", res.RayID())
+	}
+}
+
+func TestUpdateTunnelRoute_ExtraHeaders(t *testing.T) {
+	setup(Headers(http.Header{"X-Beta": []string{"client"}}))
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/10.0.0.0/16", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "request", r.Header.Get("X-Beta"))
+		assert.Equal(t, `"etag"`, r.Header.Get("If-Match"))
+		assert.Equal(t, "deadbeef", r.Header.Get("X-Auth-Key"))
+		assert.Equal(t, "", r.Header.Get("Authorization"))
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": {"network": "10.0.0.0/16"}}`)
+	})
+
+	ctx := WithExtraHeaders(context.Background(), http.Header{
+		"x-beta":        []string{"request"},
+		"If-Match":      []string{"ignored"},
+		"X-Auth-Key":    []string{"ignored"},
+		"Authorization": []string{"Bearer ignored"},
+	})
+	_, err := client.UpdateTunnelRoute(ctx, AccountIdentifier(testAccountID), TunnelRoutesUpdateParams{Network: "10.0.0.0/16", Comment: "updated", IfMatch: `"etag"`})
+	assert.NoError(t, err)
+}
+
+func TestImportTunnelRoutes(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var mu sync.Mutex
+	created := map[string]TunnelRoutesCreateParams{}
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method, "Expected method 'POST', got %s", r.Method)
+		network := strings.TrimPrefix(r.URL.Path, "/accounts/"+testAccountID+"/teamnet/routes/network/")
+
+		var params TunnelRoutesCreateParams
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&params))
+		mu.Lock()
+		created[network] = params
+		mu.Unlock()
+
+		w.Header().Set("content-type", "application/json")
+		if network == "10.2.0.0/16" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"success": false, "errors": [{"code": 1014, "message": "route already exists"}], "messages": [], "result": null}`)
+			return
+		}
+		fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": {"network": "%s", "tunnel_id": "%s", "comment": "%s"}}`, network, params.TunnelID, params.Comment)
+	})
+
+	table := `# office networks
+10.0.0.0/16 tunnel-a London  office
+
+2001:db8::/32	tunnel-b
+10.1.0.0 tunnel-a
+10.2.0.0/16 tunnel-a
+10.3.0.0/16
+`
+
+	results, err := client.ImportTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), strings.NewReader(table))
+
+	var bulkErr *TunnelRouteBulkError
+	if assert.ErrorAs(t, err, &bulkErr) {
+		assert.Len(t, bulkErr.Errors, 3)
+		assert.Contains(t, bulkErr.Error(), "line 5: 10.1.0.0")
+	}
+	assert.ErrorIs(t, err, ErrInvalidNetworkCIDR)
+	assert.ErrorIs(t, err, ErrInvalidTunnelRouteImportLine)
+	assert.ErrorIs(t, err, ErrTunnelRouteExists)
+
+	if assert.Len(t, results, 5) {
+		lines := []int{}
+		for _, result := range results {
+			lines = append(lines, result.Line)
+		}
+		assert.Equal(t, []int{2, 4, 5, 6, 7}, lines)
+
+		assert.NoError(t, results[0].Err)
+		assert.Equal(t, "London  office", results[0].Route.Comment)
+		assert.NoError(t, results[1].Err)
+		assert.Equal(t, "tunnel-b", results[1].Route.TunnelID)
+		assert.ErrorIs(t, results[2].Err, ErrInvalidNetworkCIDR)
+		assert.ErrorIs(t, results[3].Err, ErrTunnelRouteExists)
+		assert.ErrorIs(t, results[4].Err, ErrInvalidTunnelRouteImportLine)
+	}
+
+	assert.Equal(t, map[string]TunnelRoutesCreateParams{
+		"10.0.0.0/16":   {TunnelID: "tunnel-a", Comment: "London  office"},
+		"2001:db8::/32": {TunnelID: "tunnel-b"},
+		"10.2.0.0/16":   {TunnelID: "tunnel-a"},
+	}, created)
+}
+
+func TestDeleteTunnelRoutesByFilter(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		assert.Equal(t, "prod", r.URL.Query().Get("comment"))
+		assert.Equal(t, "false", r.URL.Query().Get("is_deleted"))
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": [
+			  {"network": "10.0.0.0/16", "comment": "prod"},
+			  {"network": "10.1.0.0/16", "comment": "prod", "virtual_network_id": "vnet"},
+			  {"network": "10.2.0.0/16", "comment": "prod"}
+			]
+		  }`)
+	})
+
+	var mu sync.Mutex
+	deleted := map[string]string{}
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method, "Expected method 'DELETE', got %s", r.Method)
+		network := strings.TrimPrefix(r.URL.Path, "/accounts/"+testAccountID+"/teamnet/routes/network/")
+		mu.Lock()
+		deleted[network] = r.URL.Query().Get("virtual_network_id")
+		mu.Unlock()
+
+		w.Header().Set("content-type", "application/json")
+		if network == "10.2.0.0/16" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"success": false, "errors": [{"code": 1000, "message": "failed"}], "messages": [], "result": null}`)
+			return
+		}
+		fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": {"network": "%s"}}`, network)
+	})
+
+	_, err := client.DeleteTunnelRoutesByFilter(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesDeleteByFilterParams{
+		Filter: TunnelRoutesListParams{Comment: "prod"},
+	})
+	assert.ErrorIs(t, err, ErrDeleteNotConfirmed)
+	assert.Empty(t, deleted)
+
+	results, err := client.DeleteTunnelRoutesByFilter(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesDeleteByFilterParams{
+		Filter:      TunnelRoutesListParams{Comment: "prod"},
+		Confirm:     true,
+		MaxInFlight: 2,
+	})
+	var bulkErr *TunnelRouteBulkError
+	if assert.ErrorAs(t, err, &bulkErr) {
+		assert.Len(t, bulkErr.Errors, 1)
+	}
+	if assert.Len(t, results, 3) {
+		assert.NoError(t, results[0].Err)
+		assert.NoError(t, results[1].Err)
+		assert.Error(t, results[2].Err)
+	}
+	assert.Equal(t, map[string]string{"10.0.0.0/16": "", "10.1.0.0/16": "vnet", "10.2.0.0/16": ""}, deleted)
+}
+
+func TestDeleteTunnelWithRoutes(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		assert.Equal(t, testTunnelID, r.URL.Query().Get("tunnel_id"))
+		assert.Equal(t, "false", r.URL.Query().Get("is_deleted"))
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": [
+			  {"network": "10.0.0.0/16", "tunnel_id": "%[1]s"},
+			  {"network": "10.1.0.0/16", "tunnel_id": "%[1]s"}
+			]
+		  }`, testTunnelID)
+	})
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method, "Expected method 'DELETE', got %s", r.Method)
+		network := strings.TrimPrefix(r.URL.Path, "/accounts/"+testAccountID+"/teamnet/routes/network/")
+		w.Header().Set("content-type", "application/json")
+		if network == "10.1.0.0/16" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"success": false, "errors": [{"code": 1000, "message": "failed"}], "messages": [], "result": null}`)
+			return
+		}
+		fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": {"network": "%s"}}`, network)
+	})
+
+	tunnelDeleted := false
+	mux.HandleFunc("/accounts/"+testAccountID+"/cfd_tunnel/"+testTunnelID, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method, "Expected method 'DELETE', got %s", r.Method)
+		tunnelDeleted = true
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, loadFixture("tunnel", "single_full"))
+	})
+
+	_, err := client.DeleteTunnelWithRoutes(context.Background(), AccountIdentifier(testAccountID), "")
+	assert.ErrorIs(t, err, ErrMissingTunnelID)
+
+	results, err := client.DeleteTunnelWithRoutes(context.Background(), AccountIdentifier(testAccountID), testTunnelID)
+	var bulkErr *TunnelRouteBulkError
+	if assert.ErrorAs(t, err, &bulkErr) {
+		assert.Len(t, bulkErr.Errors, 1)
+	}
+	if assert.Len(t, results, 2) {
+		assert.NoError(t, results[0].Err)
+		assert.Error(t, results[1].Err)
+	}
+	assert.True(t, tunnelDeleted)
+}
+
+func TestUpdateTunnelRoute_IfMatch(t *testing.T) {
+	setup()
+	defer teardown()
+
+	routeID := "e2ba5a4e-9a52-4c02-a5a6-1b1ad0ee0f6b"
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/"+routeID, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": {"id": "%s", "network": "10.0.0.0/16"}}`, routeID)
+	})
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/10.0.0.0/16", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method, "Expected method 'PATCH', got %s", r.Method)
+		w.Header().Set("content-type", "application/json")
+
+		if r.Header.Get("If-Match") != `"v1"` {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			fmt.Fprint(w, `{"success": false, "errors": [{"code": 1000, "message": "precondition failed"}], "messages": [], "result": null}`)
+			return
+		}
+
+		w.Header().Set("ETag", `"v2"`)
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": {"network": "10.0.0.0/16", "comment": "updated"}}`)
+	})
+
+	route, err := client.GetTunnelRoute(context.Background(), AccountIdentifier(testAccountID), routeID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `"v1"`, route.ETag)
+	}
+
+	updated, err := client.UpdateTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesUpdateParams{
+		Network: "10.0.0.0/16",
+		Comment: "updated",
+		IfMatch: route.ETag,
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, `"v2"`, updated.ETag)
+	}
+
+	_, err = client.UpdateTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesUpdateParams{
+		Network: "10.0.0.0/16",
+		Comment: "stale",
+		IfMatch: `"v0"`,
+	})
+	assert.ErrorIs(t, err, ErrTunnelRouteConflict)
+}
+
+func TestTunnelRoutes_Operations(t *testing.T) {
+	ctx := context.Background()
+	account := AccountIdentifier(testAccountID)
+	routeID := "e2ba5a4e-9a52-4c02-a5a6-1b1ad0ee0f6b"
+
+	operations := map[string]struct {
+		call   func(rc *ResourceContainer) error
+		method string
+		path   string
+		query  string
+	}{
+		"list": {
+			call: func(rc *ResourceContainer) error {
+				_, _, err := client.ListTunnelRoutes(ctx, rc, TunnelRoutesListParams{Comment: "a b", VirtualNetworkID: "vnet"})
+				return err
+			},
+			method: http.MethodGet,
+			path:   "/accounts/" + testAccountID + "/teamnet/routes",
+			query:  "comment=a+b&per_page=100&virtual_network_id=vnet",
+		},
+		"get": {
+			call: func(rc *ResourceContainer) error {
+				_, err := client.GetTunnelRoute(ctx, rc, routeID)
+				return err
+			},
+			method: http.MethodGet,
+			path:   "/accounts/" + testAccountID + "/teamnet/routes/" + routeID,
+		},
+		"create": {
+			call: func(rc *ResourceContainer) error {
+				_, err := client.CreateTunnelRoute(ctx, rc, TunnelRoutesCreateParams{Network: "10.0.0.0/16", TunnelID: testTunnelID})
+				return err
+			},
+			method: http.MethodPost,
+			path:   "/accounts/" + testAccountID + "/teamnet/routes/network/10.0.0.0%2F16",
+		},
+		"update": {
+			call: func(rc *ResourceContainer) error {
+				_, err := client.UpdateTunnelRoute(ctx, rc, TunnelRoutesUpdateParams{Network: "10.0.0.0/16", TunnelID: testTunnelID})
+				return err
+			},
+			method: http.MethodPatch,
+			path:   "/accounts/" + testAccountID + "/teamnet/routes/network/10.0.0.0%2F16",
+		},
+		"delete": {
+			call: func(rc *ResourceContainer) error {
+				_, err := client.DeleteTunnelRoute(ctx, rc, TunnelRoutesDeleteParams{Network: "10.0.0.0/16", VirtualNetworkID: "vnet"})
+				return err
+			},
+			method: http.MethodDelete,
+			path:   "/accounts/" + testAccountID + "/teamnet/routes/network/10.0.0.0%2F16",
+			query:  "virtual_network_id=vnet",
+		},
+	}
+
+	responses := map[string]struct {
+		status int
+		body   string
+		check  func(t *testing.T, err error)
+	}{
+		"success": {
+			status: http.StatusOK,
+			body:   `{"success": true, "errors": [], "messages": [], "result": null}`,
+			check: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		"malformed JSON": {
+			status: http.StatusOK,
+			body:   `{"success": true, "result": `,
+			check: func(t *testing.T, err error) {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), errUnmarshalError)
+				}
+			},
+		},
+		"API error": {
+			status: http.StatusBadRequest,
+			body:   `{"success": false, "errors": [{"code": 1001, "message": "bad request"}], "messages": [], "result": null}`,
+			check: func(t *testing.T, err error) {
+				var reqErr *RequestError
+				if assert.ErrorAs(t, err, &reqErr) {
+					assert.True(t, reqErr.InternalErrorCodeIs(1001))
+				}
+			},
+		},
+	}
+
+	for opName, op := range operations {
+		t.Run(opName+"/missing account ID", func(t *testing.T) {
+			assert.ErrorIs(t, op.call(AccountIdentifier("")), ErrMissingAccountID)
+		})
+
+		for respName, resp := range responses {
+			t.Run(opName+"/"+respName, func(t *testing.T) {
+				setup()
+				defer teardown()
+
+				requests := 0
+				mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+					requests++
+					assert.Equal(t, op.method, r.Method)
+					assert.Equal(t, op.path, r.URL.EscapedPath())
+					assert.Equal(t, op.query, r.URL.RawQuery)
+					w.Header().Set("content-type", "application/json")
+					w.WriteHeader(resp.status)
+					fmt.Fprint(w, resp.body)
+				})
+
+				resp.check(t, op.call(account))
+				assert.Equal(t, 1, requests)
+			})
+		}
+	}
+}
+
+func TestTunnelRoutes_PathEscaping(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var wantPath string
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, wantPath, r.URL.EscapedPath())
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": {}}`)
+	})
+
+	prefix := "/accounts/" + testAccountID + "/teamnet/routes/network/"
+	for network, escaped := range map[string]string{
+		"10.0.0.0/8":     "10.0.0.0%2F8",
+		"2001:db8::/32":  "2001:db8::%2F32",
+		"::ffff:0:0/96":  "::ffff:0:0%2F96",
+		"192.0.2.128/25": "192.0.2.128%2F25",
+	} {
+		wantPath = prefix + escaped
+		_, err := client.CreateTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesCreateParams{Network: network, TunnelID: testTunnelID})
+		assert.NoError(t, err, network)
+
+		_, err = client.UpdateTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesUpdateParams{Network: network, Comment: "updated"})
+		assert.NoError(t, err, network)
+
+		_, err = client.DeleteTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesDeleteParams{Network: network})
+		assert.NoError(t, err, network)
+	}
+}
+
+func TestNewTunnelRoutesCreateParams(t *testing.T) {
+	params, err := NewTunnelRoutesCreateParams(testTunnelID, "10.0.0.0/16")
+	if assert.NoError(t, err) {
+		assert.Equal(t, TunnelRoutesCreateParams{TunnelID: testTunnelID, Network: "10.0.0.0/16"}, params)
+	}
+
+	_, err = NewTunnelRoutesCreateParams("", "10.0.0.0/16")
+	assert.ErrorIs(t, err, ErrMissingTunnelID)
+
+	_, err = NewTunnelRoutesCreateParams(testTunnelID, "")
+	assert.ErrorIs(t, err, ErrMissingNetwork)
+
+	// Passing the arguments the wrong way around is caught by the CIDR check.
+	_, err = NewTunnelRoutesCreateParams("10.0.0.0/16", testTunnelID)
+	assert.ErrorIs(t, err, ErrInvalidNetworkCIDR)
+}
+
+func TestCountTunnelRoutes(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		assert.Equal(t, "comment=prod&page=1&per_page=1", r.URL.RawQuery)
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": [{"network": "10.0.0.0/16"}],
+			"result_info": {"page": 1, "per_page": 1, "count": 1, "total_count": 42}
+		  }`)
+	})
+
+	count, err := client.CountTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesListParams{
+		Comment:           "prod",
+		PaginationOptions: PaginationOptions{Page: 3, PerPage: 50},
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, 42, count)
+	}
+
+	_, err = client.CountTunnelRoutes(context.Background(), AccountIdentifier(""), TunnelRoutesListParams{})
+	assert.ErrorIs(t, err, ErrMissingAccountID)
+}
+
+func TestCountTunnelRoutes_TunnelIDs(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var requested []string
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		tunnelID := r.URL.Query().Get("tunnel_id")
+		requested = append(requested, tunnelID)
+		total := map[string]int{"a": 3, "b": 4}[tunnelID]
+
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": [{"network": "10.0.0.0/16"}],
+			"result_info": {"page": 1, "per_page": 1, "count": 1, "total_count": %d}
+		  }`, total)
+	})
+
+	count, err := client.CountTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesListParams{TunnelIDs: []string{"a", "b", "a"}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, 7, count)
+	}
+	assert.Equal(t, []string{"a", "b"}, requested)
+}
+
+func TestListTunnelRoutes_TunnelType(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, TunnelRouteTypeWarpConnector, r.URL.Query().Get("tun_types"))
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": [{"network": "10.0.0.0/16", "tun_type": "warp_connector"}]
+		  }`)
+	})
+
+	routes, _, err := client.ListTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesListParams{
+		TunnelTypes: []string{TunnelRouteTypeWarpConnector},
+	})
+	if assert.NoError(t, err) && assert.Len(t, routes, 1) {
+		assert.Equal(t, TunnelRouteTypeWarpConnector, routes[0].TunnelType)
+	}
+}
+
+func TestIsTunnelRouteError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/10.0.0.0/16", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"success": false, "errors": [{"code": 1014, "message": "route already exists"}], "messages": [], "result": null}`)
+	})
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/10.1.0.0/16", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"success": false, "errors": [{"code": 1000, "message": "not found"}], "messages": [], "result": null}`)
+	})
+
+	_, err := client.CreateTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesCreateParams{Network: "10.0.0.0/16", TunnelID: testTunnelID})
+	assert.True(t, IsTunnelRouteError(err, TunnelRouteErrorCodeExists))
+	assert.False(t, IsTunnelRouteError(err, 1000))
+
+	_, err = client.DeleteTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesDeleteParams{Network: "10.1.0.0/16"})
+	assert.True(t, IsTunnelRouteError(err, 1000))
+
+	assert.False(t, IsTunnelRouteError(ErrMissingNetwork, TunnelRouteErrorCodeExists))
+	assert.False(t, IsTunnelRouteError(nil, TunnelRouteErrorCodeExists))
+}
+
+func TestListTunnelRoutesSorted(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": [
+			  {"network": "2001:db8::/32", "created_at": "2021-01-03T00:00:00Z"},
+			  {"network": "10.1.0.0/16", "created_at": "2021-01-01T00:00:00Z"},
+			  {"network": "invalid"},
+			  {"network": "10.0.0.0/8", "created_at": "2021-01-02T00:00:00Z"},
+			  {"network": "9.0.0.0/8", "created_at": "2021-01-02T00:00:00Z"},
+			  {"network": "10.0.0.0/16"}
+			]
+		  }`)
+	})
+
+	testCases := map[string]struct {
+		sortBy    TunnelRoutesSortBy
+		direction OrderDirection
+		want      []string
+	}{
+		"network ascending": {
+			sortBy:    TunnelRoutesSortByNetwork,
+			direction: OrderDirectionAsc,
+			want:      []string{"9.0.0.0/8", "10.0.0.0/8", "10.0.0.0/16", "10.1.0.0/16", "2001:db8::/32", "invalid"},
+		},
+		"network descending": {
+			sortBy:    TunnelRoutesSortByNetwork,
+			direction: OrderDirectionDesc,
+			want:      []string{"invalid", "2001:db8::/32", "10.1.0.0/16", "10.0.0.0/16", "10.0.0.0/8", "9.0.0.0/8"},
+		},
+		"created at ascending": {
+			sortBy:    TunnelRoutesSortByCreatedAt,
+			direction: OrderDirectionAsc,
+			want:      []string{"10.1.0.0/16", "9.0.0.0/8", "10.0.0.0/8", "2001:db8::/32", "10.0.0.0/16", "invalid"},
+		},
+		"created at descending": {
+			sortBy:    TunnelRoutesSortByCreatedAt,
+			direction: OrderDirectionDesc,
+			want:      []string{"2001:db8::/32", "10.0.0.0/8", "9.0.0.0/8", "10.1.0.0/16", "invalid", "10.0.0.0/16"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			routes, err := client.ListTunnelRoutesSorted(context.Background(), AccountIdentifier(testAccountID), ListTunnelRoutesSortedParams{SortBy: tc.sortBy, Direction: tc.direction})
+			if assert.NoError(t, err) {
+				got := []string{}
+				for _, route := range routes {
+					got = append(got, route.Network)
+				}
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestGetTunnelRouteForIP_VirtualNetwork(t *testing.T) {
+	setup()
+	defer teardown()
+
+	routes := map[string]string{
+		"vnet-a": "f70ff985-a4ef-4643-bbbc-4a0ed4fc8415",
+		"vnet-b": "d3f1b2c4-5e6a-4b7c-8d9e-0f1a2b3c4d5e",
+	}
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/ip/10.1.0.137", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		vnet := r.URL.Query().Get("virtual_network_id")
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": {"network": "10.1.0.0/16", "tunnel_id": "%s", "virtual_network_id": "%s"}
+		  }`, routes[vnet], vnet)
+	})
+
+	for vnet, tunnelID := range routes {
+		route, err := client.GetTunnelRouteForIP(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesForIPParams{
+			Network:          "10.1.0.137",
+			VirtualNetworkID: vnet,
+		})
+		if assert.NoError(t, err) {
+			assert.Equal(t, "10.1.0.0/16", route.Network)
+			assert.Equal(t, vnet, route.VirtualNetworkID)
+			assert.Equal(t, tunnelID, route.TunnelID)
+		}
+	}
+}
+
+func TestListTunnelRoutesMap(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var result string
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": %s}`, result)
+	})
+
+	result = `[
+		{"id": "1", "network": "10.0.0.0/16"},
+		{"id": "2", "network": "10.0.0.0/16", "virtual_network_id": "vnet"},
+		{"id": "3", "network": "10.1.0.0/16"}
+	]`
+	routes, err := client.ListTunnelRoutesMap(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesListParams{})
+	if assert.NoError(t, err) {
+		assert.Len(t, routes, 3)
+		assert.Equal(t, "1", routes[TunnelRouteKey{Network: "10.0.0.0/16"}].ID)
+		assert.Equal(t, "2", routes[TunnelRouteKey{Network: "10.0.0.0/16", VirtualNetworkID: "vnet"}].ID)
+		assert.Equal(t, "3", routes[TunnelRoute{Network: "10.1.0.0/16"}.Key()].ID)
+	}
+
+	result = `[
+		{"id": "1", "network": "10.0.0.0/16"},
+		{"id": "2", "network": "10.0.0.0/16"}
+	]`
+	_, err = client.ListTunnelRoutesMap(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesListParams{})
+	assert.ErrorIs(t, err, ErrDuplicateTunnelRoute)
+}
+
+func TestExportTunnelRoutes(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		w.Header().Set("content-type", "application/json")
+		if r.URL.Query().Get("page") == "1" {
+			fmt.Fprint(w, `{
+				"success": true,
+				"errors": [],
+				"messages": [],
+				"result": [{"network": "10.0.0.0/16", "tunnel_id": "a", "tunnel_name": "blog", "comment": "with, comma", "created_at": "2021-01-25T18:22:34.317854Z"}],
+				"result_info": {"page": 1, "per_page": 1, "total_pages": 2}
+			}`)
+			return
+		}
+		fmt.Fprint(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": [{"network": "2001:db8::/32", "tunnel_id": "b", "tunnel_name": "docs", "created_at": "2021-01-26T04:22:34+10:00"}],
+			"result_info": {"page": 2, "per_page": 1, "total_pages": 2}
+		}`)
+	})
+
+	var buf bytes.Buffer
+	err := client.ExportTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), ExportTunnelRoutesParams{Writer: &buf, Format: TunnelRoutesExportCSV})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "network,tunnel_id,tunnel_name,comment,created_at\n"+
+			"10.0.0.0/16,a,blog,\"with, comma\",2021-01-25T18:22:34.317854Z\n"+
+			"2001:db8::/32,b,docs,,2021-01-25T18:22:34Z\n", buf.String())
+	}
+
+	buf.Reset()
+	err = client.ExportTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), ExportTunnelRoutesParams{Writer: &buf, Format: TunnelRoutesExportJSONLines})
+	if assert.NoError(t, err) {
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		if assert.Len(t, lines, 2) {
+			var route TunnelRoute
+			assert.NoError(t, json.Unmarshal([]byte(lines[1]), &route))
+			assert.Equal(t, "2001:db8::/32", route.Network)
+			assert.Contains(t, lines[1], `"created_at":"2021-01-25T18:22:34Z"`)
+		}
+	}
+
+	err = client.ExportTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), ExportTunnelRoutesParams{Writer: &buf, Format: "xml"})
+	assert.ErrorIs(t, err, ErrInvalidExportFormat)
+
+	err = client.ExportTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), ExportTunnelRoutesParams{Format: TunnelRoutesExportCSV})
+	assert.ErrorIs(t, err, ErrMissingExportWriter)
+}
+
+func TestValidateTunnelRoutes(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "false", r.URL.Query().Get("is_deleted"))
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": [
+			  {"network": "10.0.0.0/16", "tunnel_id": "active", "tun_type": "cfd_tunnel"},
+			  {"network": "10.1.0.0/16", "tunnel_id": "deleted", "tun_type": "cfd_tunnel"},
+			  {"network": "10.2.0.0/16", "tunnel_id": "missing"},
+			  {"network": "10.3.0.0/16", "tunnel_id": "connector", "tun_type": "warp_connector"},
+			  {"network": "10.4.0.0/16", "tunnel_id": "ipsec", "tun_type": "ip_sec"}
+			]
+		  }`)
+	})
+	mux.HandleFunc("/accounts/"+testAccountID+"/cfd_tunnel", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "", r.URL.Query().Get("is_deleted"))
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": [
+			  {"id": "active", "name": "active"},
+			  {"id": "deleted", "name": "deleted", "deleted_at": "2021-01-25T18:22:34Z"}
+			]
+		  }`)
+	})
+	mux.HandleFunc("/accounts/"+testAccountID+"/warp_connector", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": [{"id": "connector", "name": "connector"}]}`)
+	})
+
+	orphaned, err := client.ValidateTunnelRoutes(context.Background(), AccountIdentifier(testAccountID))
+	if assert.NoError(t, err) && assert.Len(t, orphaned, 2) {
+		assert.Equal(t, "10.1.0.0/16", orphaned[0].Route.Network)
+		assert.Equal(t, TunnelRouteOrphanTunnelDeleted, orphaned[0].Reason)
+		assert.Equal(t, "10.2.0.0/16", orphaned[1].Route.Network)
+		assert.Equal(t, TunnelRouteOrphanTunnelNotFound, orphaned[1].Reason)
+	}
+}
+
+func TestListTunnelRoutesByFamily(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var result string
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": %s}`, result)
+	})
+
+	result = `[
+		{"network": "10.0.0.0/16"},
+		{"network": "2001:db8::/32"},
+		{"network": "::ffff:10.0.0.0/104"},
+		{"network": "192.168.0.0/24"}
+	]`
+
+	testCases := map[string]struct {
+		family TunnelRouteNetworkFamily
+		want   []string
+	}{
+		"IPv4": {family: TunnelRouteNetworkIPv4, want: []string{"10.0.0.0/16", "::ffff:10.0.0.0/104", "192.168.0.0/24"}},
+		"IPv6": {family: TunnelRouteNetworkIPv6, want: []string{"2001:db8::/32"}},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			routes, err := client.ListTunnelRoutesByFamily(context.Background(), AccountIdentifier(testAccountID), ListTunnelRoutesByFamilyParams{Family: tc.family})
+			if assert.NoError(t, err) {
+				got := []string{}
+				for _, route := range routes {
+					got = append(got, route.Network)
+				}
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+
+	result = `[{"network": "10.0.0.0/16"}, {"network": "bogus"}]`
+	routes, err := client.ListTunnelRoutesByFamily(context.Background(), AccountIdentifier(testAccountID), ListTunnelRoutesByFamilyParams{Family: TunnelRouteNetworkIPv4})
+	assert.ErrorIs(t, err, ErrInvalidNetworkCIDR)
+	assert.Contains(t, err.Error(), `"bogus"`)
+	assert.Len(t, routes, 1)
+
+	_, err = client.ListTunnelRoutesByFamily(context.Background(), AccountIdentifier(testAccountID), ListTunnelRoutesByFamilyParams{Family: "v5"})
+	assert.ErrorIs(t, err, ErrInvalidNetworkFamily)
+}
+
+func TestTunnelRouteMetadata(t *testing.T) {
+	testCases := map[string]struct {
+		comment string
+		meta    map[string]string
+		want    string
+	}{
+		"no existing comment": {
+			comment: "",
+			meta:    map[string]string{"env": "prod"},
+			want:    `meta:{"env":"prod"}`,
+		},
+		"free text is kept": {
+			comment: "web servers",
+			meta:    map[string]string{"env": "prod", "owner": "team-x"},
+			want:    `web servers meta:{"env":"prod","owner":"team-x"}`,
+		},
+		"existing block is replaced": {
+			comment: `web servers meta:{"env":"staging","extra":"dropped"}`,
+			meta:    map[string]string{"env": "prod"},
+			want:    `web servers meta:{"env":"prod"}`,
+		},
+		"empty meta removes block": {
+			comment: `web servers meta:{"env":"staging"}`,
+			meta:    nil,
+			want:    "web servers",
+		},
+		"braces in free text": {
+			comment: "uses {templates} and meta:{broken",
+			meta:    map[string]string{"env": "prod"},
+			want:    `uses {templates} and meta:{broken meta:{"env":"prod"}`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, err := SetTunnelRouteMetadata(tc.comment, tc.meta)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.want, got)
+			}
+
+			meta, ok := ParseTunnelRouteMetadata(got)
+			assert.Equal(t, len(tc.meta) > 0, ok)
+			if len(tc.meta) > 0 {
+				assert.Equal(t, tc.meta, meta)
+			}
+		})
+	}
+}
+
+func TestTunnelRouteMetadata_RoundTripUnknownKeys(t *testing.T) {
+	comment := `web servers meta:{"env":"prod","added-by-another-tool":"x"}`
+
+	meta, ok := ParseTunnelRouteMetadata(comment)
+	if assert.True(t, ok) {
+		meta["owner"] = "team-x"
+		updated, err := SetTunnelRouteMetadata(comment, meta)
+		if assert.NoError(t, err) {
+			assert.Equal(t, `web servers meta:{"added-by-another-tool":"x","env":"prod","owner":"team-x"}`, updated)
+		}
+	}
+
+	_, ok = ParseTunnelRouteMetadata("plain comment")
+	assert.False(t, ok)
+}
+
+func TestSetTunnelRouteMetadata_TooLong(t *testing.T) {
+	text := strings.Repeat("a", TunnelRouteCommentMaxLength-10)
+
+	_, err := SetTunnelRouteMetadata(text, nil)
+	assert.NoError(t, err)
+
+	_, err = SetTunnelRouteMetadata(text, map[string]string{"env": "prod"})
+	assert.ErrorIs(t, err, ErrCommentTooLong)
+}
+
+func TestTunnelRoutes_AccountRouteRoot(t *testing.T) {
+	setup(UsingAccountRouteRoot("tenants"))
+	defer teardown()
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method, "Expected method 'POST', got %s", r.Method)
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": {"network": "10.0.0.0/16", "tunnel_id": "%s"}}`, testTunnelID)
+	}
+
+	mux.HandleFunc("/tenants/"+testAccountID+"/teamnet/routes/network/10.0.0.0/16", handler)
+
+	route, err := client.CreateTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesCreateParams{
+		TunnelID: testTunnelID,
+		Network:  "10.0.0.0/16",
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "10.0.0.0/16", route.Network)
+	}
+
+	req, err := client.PlanCreateTunnelRoute(AccountIdentifier(testAccountID), TunnelRoutesCreateParams{
+		TunnelID: testTunnelID,
+		Network:  "10.0.0.0/16",
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, client.BaseURL+"/tenants/"+testAccountID+"/teamnet/routes/network/10.0.0.0%2F16", req.URL)
+	}
+}
+
+func TestWatchTunnelRoutes(t *testing.T) {
+	setup()
+	defer teardown()
+
+	snapshots := []string{
+		`[{"id": "a", "network": "10.0.0.0/16", "comment": "old"}, {"id": "b", "network": "10.1.0.0/16"}]`,
+		`[{"id": "a", "network": "10.0.0.0/16", "comment": "new"}, {"id": "c", "network": "10.2.0.0/16"}]`,
+	}
+
+	var mu sync.Mutex
+	polls := 0
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		mu.Lock()
+		snapshot := snapshots[len(snapshots)-1]
+		if polls < len(snapshots) {
+			snapshot = snapshots[polls]
+		}
+		polls++
+		mu.Unlock()
+
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": %s}`, snapshot)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes, errc := client.WatchTunnelRoutes(ctx, AccountIdentifier(testAccountID), WatchTunnelRoutesParams{Interval: 10 * time.Millisecond})
+
+	var got []TunnelRouteChange
+	for len(got) < 3 {
+		select {
+		case change := <-changes:
+			got = append(got, change)
+		case err := <-errc:
+			t.Fatalf("unexpected error: %s", err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for changes")
+		}
+	}
+
+	if assert.Len(t, got, 3) {
+		assert.Equal(t, TunnelRouteAdded, got[0].Type)
+		assert.Equal(t, "c", got[0].Route.ID)
+		assert.Equal(t, TunnelRouteUpdated, got[1].Type)
+		assert.Equal(t, "new", got[1].Route.Comment)
+		assert.Equal(t, TunnelRouteRemoved, got[2].Type)
+		assert.Equal(t, "b", got[2].Route.ID)
+	}
+
+	cancel()
+	for range changes {
+		t.Error("unexpected change after cancellation")
+	}
+	for err := range errc {
+		assert.NoError(t, err)
+	}
+}
+
+func TestWatchTunnelRoutes_InvalidInterval(t *testing.T) {
+	setup()
+	defer teardown()
+
+	changes, errc := client.WatchTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), WatchTunnelRoutesParams{})
+	assert.ErrorIs(t, <-errc, ErrInvalidWatchInterval)
+	_, ok := <-changes
+	assert.False(t, ok)
+}
+
+func TestWatchTunnelRoutes_MissingAccountID(t *testing.T) {
+	setup()
+	defer teardown()
+
+	changes, errc := client.WatchTunnelRoutes(context.Background(), AccountIdentifier(""), WatchTunnelRoutesParams{Interval: time.Millisecond})
+	assert.ErrorIs(t, <-errc, ErrMissingAccountID)
+	_, ok := <-errc
+	assert.False(t, ok)
+	_, ok = <-changes
+	assert.False(t, ok)
+}
+
+type recordingSpan struct {
+	name       string
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+func (s *recordingSpan) SetAttribute(key string, value interface{}) { s.attributes[key] = value }
+func (s *recordingSpan) RecordError(err error)                      { s.err = err }
+func (s *recordingSpan) End()                                       { s.ended = true }
+
+type recordingTracer struct {
+	spans []*recordingSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	span := &recordingSpan{name: name, attributes: map[string]interface{}{}}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func (t *recordingTracer) Inject(ctx context.Context, header http.Header) {
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+}
+
+func TestTunnelRoutesTracing(t *testing.T) {
+	tracer := &recordingTracer{}
+	setup(UsingTracer(tracer))
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", r.Header.Get("traceparent"))
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": []}`)
+	})
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/missing", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"success": false, "errors": [{"code": 1000, "message": "route not found"}], "messages": [], "result": null}`)
+	})
+
+	_, _, err := client.ListTunnelRoutes(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesListParams{TunnelID: testTunnelID})
+	assert.NoError(t, err)
+
+	_, err = client.GetTunnelRoute(context.Background(), AccountIdentifier(testAccountID), "missing")
+	assert.Error(t, err)
+
+	if assert.Len(t, tracer.spans, 2) {
+		list := tracer.spans[0]
+		assert.Equal(t, "cloudflare.ListTunnelRoutes", list.name)
+		assert.Equal(t, map[string]interface{}{
+			SpanAttributeAccountID:      testAccountID,
+			SpanAttributeTunnelID:       testTunnelID,
+			SpanAttributeHTTPStatusCode: http.StatusOK,
+		}, list.attributes)
+		assert.NoError(t, list.err)
+		assert.True(t, list.ended)
+
+		get := tracer.spans[1]
+		assert.Equal(t, "cloudflare.GetTunnelRoute", get.name)
+		assert.Equal(t, http.StatusNotFound, get.attributes[SpanAttributeHTTPStatusCode])
+		assert.True(t, IsNotFound(get.err))
+		assert.True(t, get.ended)
+	}
+}
+
+func TestCompareNetworks(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"9.0.0.0/8", "10.0.0.0/8", -1},
+		{"10.0.0.0/8", "10.0.2.0/24", -1},
+		{"10.0.0.0/8", "10.0.0.0/16", -1},
+		{"10.0.0.0/16", "10.0.0.0/16", 0},
+		{"192.168.0.0/16", "::/0", -1},
+		{"::ffff:10.0.0.0/104", "10.0.0.0/8", 0},
+		{"2001:db8::/32", "invalid", -1},
+		{"invalid", "invalid", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			got := CompareNetworks(tt.a, tt.b)
+			switch {
+			case tt.want < 0:
+				assert.Negative(t, got)
+			case tt.want > 0:
+				assert.Positive(t, got)
+			default:
+				assert.Zero(t, got)
+			}
+			if tt.want != 0 {
+				assert.Equal(t, got < 0, CompareNetworks(tt.b, tt.a) > 0)
+			}
+		})
+	}
+}
+
+func TestByNetwork(t *testing.T) {
+	routes := []TunnelRoute{
+		{Network: "2001:db8::/32"},
+		{Network: "10.0.2.0/24"},
+		{Network: "invalid"},
+		{Network: "9.0.0.0/8"},
+		{Network: "10.0.0.0/8"},
+	}
+
+	sort.Sort(ByNetwork(routes))
+
+	networks := []string{}
+	for _, route := range routes {
+		networks = append(networks, route.Network)
+	}
+	assert.Equal(t, []string{"9.0.0.0/8", "10.0.0.0/8", "10.0.2.0/24", "2001:db8::/32", "invalid"}, networks)
+}
+
+func TestCreateTunnelRoute_TunnelNotFound(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/10.0.0.0/16", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"success": false, "errors": [{"code": 1000, "message": "not found"}], "messages": [], "result": null}`)
+	})
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/10.1.0.0/16", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"success": false, "errors": [{"code": 1000, "message": "not found"}], "messages": [], "result": null}`)
+	})
+	mux.HandleFunc("/accounts/"+testAccountID+"/cfd_tunnel/missing-tunnel", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		w.Header().Set("content-type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"success": false, "errors": [{"code": 1000, "message": "not found"}], "messages": [], "result": null}`)
+	})
+	mux.HandleFunc("/accounts/"+testAccountID+"/cfd_tunnel/"+testTunnelID, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": {"id": "%s", "name": "blog"}}`, testTunnelID)
+	})
+	notFound := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		w.Header().Set("content-type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"success": false, "errors": [{"code": 1000, "message": "not found"}], "messages": [], "result": null}`)
+	}
+	mux.HandleFunc("/accounts/"+testAccountID+"/warp_connector/missing-tunnel", notFound)
+	mux.HandleFunc("/accounts/"+testAccountID+"/cfd_tunnel/warp-tunnel", notFound)
+	mux.HandleFunc("/accounts/"+testAccountID+"/warp_connector/warp-tunnel", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": {"id": "warp-tunnel", "name": "office", "tun_type": "warp_connector"}}`)
+	})
+
+	_, err := client.CreateTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesCreateParams{Network: "10.0.0.0/16", TunnelID: "missing-tunnel"})
+	assert.ErrorIs(t, err, ErrTunnelNotFound)
+	assert.True(t, IsNotFound(err))
+	assert.Contains(t, err.Error(), "missing-tunnel")
+
+	_, err = client.UpdateTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesUpdateParams{Network: "10.0.0.0/16", TunnelID: "missing-tunnel"})
+	assert.ErrorIs(t, err, ErrTunnelNotFound)
+
+	_, err = client.UpdateTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesUpdateParams{Network: "10.1.0.0/16", TunnelID: testTunnelID})
+	assert.NotErrorIs(t, err, ErrTunnelNotFound)
+	assert.True(t, IsNotFound(err))
+
+	_, err = client.UpdateTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesUpdateParams{Network: "10.1.0.0/16", TunnelID: "warp-tunnel"})
+	assert.NotErrorIs(t, err, ErrTunnelNotFound)
+	assert.True(t, IsNotFound(err))
+}
+
+func TestGetTunnelRouteByNetwork(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		query := r.URL.Query()
+		assert.Equal(t, "false", query.Get("is_deleted"))
+		assert.Equal(t, query.Get("network_subset"), query.Get("network_superset"))
+
+		w.Header().Set("content-type", "application/json")
+		switch query.Get("network_subset") {
+		case "10.0.0.0/16":
+			fmt.Fprint(w, `{
+				"success": true,
+				"errors": [],
+				"messages": [],
+				"result": [
+				  {"id": "a", "network": "10.0.0.0/16", "virtual_network_id": "vnet-a"},
+				  {"id": "b", "network": "10.0.0.0/16", "virtual_network_id": "vnet-b"}
+				]
+			  }`)
+		default:
+			fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": []}`)
+		}
+	})
+
+	route, err := client.GetTunnelRouteByNetwork(context.Background(), AccountIdentifier(testAccountID), GetTunnelRouteByNetworkParams{Network: "10.0.0.0/16", VirtualNetworkID: "vnet-b"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "b", route.ID)
+	}
+
+	_, err = client.GetTunnelRouteByNetwork(context.Background(), AccountIdentifier(testAccountID), GetTunnelRouteByNetworkParams{Network: "10.0.0.0/16"})
+	assert.ErrorIs(t, err, ErrDuplicateTunnelRoute)
+
+	_, err = client.GetTunnelRouteByNetwork(context.Background(), AccountIdentifier(testAccountID), GetTunnelRouteByNetworkParams{Network: "10.1.0.0/16"})
+	assert.ErrorIs(t, err, ErrTunnelRouteNotFound)
+
+	_, err = client.GetTunnelRouteByNetwork(context.Background(), AccountIdentifier(testAccountID), GetTunnelRouteByNetworkParams{Network: "10.1.0.0"})
+	assert.ErrorIs(t, err, ErrInvalidNetworkCIDR)
+
+	_, err = client.GetTunnelRouteByNetwork(context.Background(), AccountIdentifier(""), GetTunnelRouteByNetworkParams{Network: "10.0.0.0/16"})
+	assert.ErrorIs(t, err, ErrMissingAccountID)
+}
+
+func TestTunnelRouteCommentTooLong(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/10.0.0.0/16", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("no request should be made for an over-length comment")
+	})
+
+	comment := strings.Repeat("é", TunnelRouteCommentMaxLength+1)
+
+	_, err := client.CreateTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesCreateParams{Network: "10.0.0.0/16", TunnelID: testTunnelID, Comment: comment})
+	assert.ErrorIs(t, err, ErrCommentTooLong)
+
+	_, err = client.UpdateTunnelRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesUpdateParams{Network: "10.0.0.0/16", Comment: comment})
+	assert.ErrorIs(t, err, ErrCommentTooLong)
+
+	_, err = client.PlanCreateTunnelRoute(AccountIdentifier(testAccountID), TunnelRoutesCreateParams{Network: "10.0.0.0/16", Comment: comment})
+	assert.ErrorIs(t, err, ErrCommentTooLong)
+
+	_, err = client.PlanCreateTunnelRoute(AccountIdentifier(testAccountID), TunnelRoutesCreateParams{Network: "10.0.0.0/16", Comment: comment[:2*TunnelRouteCommentMaxLength]})
+	assert.NoError(t, err)
+}
+
+func TestListTunnelRoutesContaining(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var query url.Values
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		query = r.URL.Query()
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": [{"network": "10.0.0.0/8"}, {"network": "10.0.0.0/16"}]}`)
+	})
+
+	routes, err := client.ListTunnelRoutesContaining(context.Background(), AccountIdentifier(testAccountID), ListTunnelRoutesContainingParams{TunnelRoutesListParams: TunnelRoutesListParams{VirtualNetworkID: "vnet"}, IP: "10.0.0.1"})
+	assert.NoError(t, err)
+	assert.Len(t, routes, 2)
+	assert.Equal(t, "10.0.0.1/32", query.Get("network_superset"))
+	assert.Equal(t, "", query.Get("network_subset"))
+	assert.Equal(t, "vnet", query.Get("virtual_network_id"))
+
+	_, err = client.ListTunnelRoutesContaining(context.Background(), AccountIdentifier(testAccountID), ListTunnelRoutesContainingParams{IP: "2001:db8::1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "2001:db8::1/128", query.Get("network_superset"))
+
+	_, err = client.ListTunnelRoutesContaining(context.Background(), AccountIdentifier(testAccountID), ListTunnelRoutesContainingParams{IP: "10.0.0.0/24"})
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.0/24", query.Get("network_superset"))
+
+	_, err = client.ListTunnelRoutesContaining(context.Background(), AccountIdentifier(testAccountID), ListTunnelRoutesContainingParams{IP: "not-an-ip"})
+	assert.ErrorIs(t, err, ErrInvalidNetworkValue)
+
+	_, err = client.ListTunnelRoutesContainedBy(context.Background(), AccountIdentifier(testAccountID), ListTunnelRoutesContainedByParams{TunnelRoutesListParams: TunnelRoutesListParams{NetworkSuperset: "10.0.0.1/32"}, Network: "10.0.0.0/8"})
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.0/8", query.Get("network_subset"))
+	assert.Equal(t, "10.0.0.1/32", query.Get("network_superset"))
+
+	_, err = client.ListTunnelRoutesContainedBy(context.Background(), AccountIdentifier(testAccountID), ListTunnelRoutesContainedByParams{Network: "10.0.0.1"})
+	assert.ErrorIs(t, err, ErrInvalidNetworkCIDR)
+}
+
+func TestTunnelRoutesStrictDecoding(t *testing.T) {
+	setup(UsingStrictDecoding(true))
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/known", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": {"id": "known", "network": "10.0.0.0/16"}}`)
+	})
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/unknown", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": {"id": "unknown", "network": "10.0.0.0/16", "new_field": {}}}`)
+	})
+
+	route, err := client.GetTunnelRoute(context.Background(), AccountIdentifier(testAccountID), "known")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "known", route.ID)
+	}
+
+	_, err = client.GetTunnelRoute(context.Background(), AccountIdentifier(testAccountID), "unknown")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), errUnmarshalError)
+		assert.Contains(t, err.Error(), "new_field")
+	}
+
+	client.strictDecoding = false
+	_, err = client.GetTunnelRoute(context.Background(), AccountIdentifier(testAccountID), "unknown")
+	assert.NoError(t, err)
+}
+
+func TestTunnelRoutesDefaultAccount(t *testing.T) {
+	setup(UsingAccountID(testAccountID))
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": [{"id": "default"}]}`)
+	})
+	mux.HandleFunc("/accounts/other/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": [{"id": "other"}]}`)
+	})
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/10.0.0.0/16", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": {"network": "10.0.0.0/16", "tunnel_id": "%s"}}`, testTunnelID)
+	})
+
+	routes, _, err := client.ListTunnelRoutes(context.Background(), AccountIdentifier(""), TunnelRoutesListParams{})
+	if assert.NoError(t, err) && assert.Len(t, routes, 1) {
+		assert.Equal(t, "default", routes[0].ID)
+	}
+
+	routes, _, err = client.ListTunnelRoutes(context.Background(), nil, TunnelRoutesListParams{})
+	if assert.NoError(t, err) && assert.Len(t, routes, 1) {
+		assert.Equal(t, "default", routes[0].ID)
+	}
+
+	routes, _, err = client.ListTunnelRoutes(context.Background(), AccountIdentifier("other"), TunnelRoutesListParams{})
+	if assert.NoError(t, err) && assert.Len(t, routes, 1) {
+		assert.Equal(t, "other", routes[0].ID)
+	}
+
+	route, err := client.CreateTunnelRoute(context.Background(), AccountIdentifier(""), TunnelRoutesCreateParams{Network: "10.0.0.0/16", TunnelID: testTunnelID})
+	if assert.NoError(t, err) {
+		assert.Equal(t, testTunnelID, route.TunnelID)
+	}
+
+	client.defaultAccountID = ""
+	_, _, err = client.ListTunnelRoutes(context.Background(), AccountIdentifier(""), TunnelRoutesListParams{})
+	assert.ErrorIs(t, err, ErrMissingAccountID)
+}
+
+func TestListTunnelRoutesAll_MaxPages(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests := 0
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		page := r.URL.Query().Get("page")
+		w.Header().Set("content-type", "application/json")
+		// a misbehaving server which always reports another page
+		fmt.Fprintf(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": [{"network": "10.%s.0.0/16"}],
+			"result_info": {"page": %s, "per_page": 1, "count": 1, "total_count": 1000000, "total_pages": 1000000}
+		  }`, page, page)
+	})
+
+	routes, _, err := client.ListTunnelRoutesAll(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesListParams{MaxPages: 3})
+	assert.ErrorIs(t, err, ErrPaginationLimitExceeded)
+	assert.Len(t, routes, 3)
+	assert.Equal(t, 3, requests)
+
+	mux.HandleFunc("/accounts/exact/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": [{"network": "10.%s.0.0/16"}],
+			"result_info": {"page": %s, "per_page": 1, "count": 1, "total_count": 3, "total_pages": 3}
+		  }`, page, page)
+	})
+
+	routes, _, err = client.ListTunnelRoutesAll(context.Background(), AccountIdentifier("exact"), TunnelRoutesListParams{MaxPages: 3})
+	assert.NoError(t, err)
+	assert.Len(t, routes, 3)
+
+	requests = 0
+	_, _, err = client.ListTunnelRoutesAll(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesListParams{MaxPages: -1})
+	assert.ErrorIs(t, err, ErrInvalidMaxPages)
+	assert.Equal(t, 0, requests)
+}
+
+func TestCreateTunnelHostRoute(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var created []string
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method, "Expected method 'POST', got %s", r.Method)
+		network := strings.TrimPrefix(r.URL.Path, "/accounts/"+testAccountID+"/teamnet/routes/network/")
+		created = append(created, network)
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": {"network": "%s", "tunnel_id": "%s"}}`, network, testTunnelID)
+	})
+
+	route, err := client.CreateTunnelHostRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesCreateParams{Network: "10.0.0.1", TunnelID: testTunnelID})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "10.0.0.1/32", route.Network)
+	}
+
+	_, err = client.CreateTunnelHostRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesCreateParams{Network: "2001:DB8::0001", TunnelID: testTunnelID})
+	assert.NoError(t, err)
+
+	_, err = client.CreateTunnelHostRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesCreateParams{Network: "10.0.0.0/24", TunnelID: testTunnelID})
+	assert.ErrorIs(t, err, ErrInvalidNetworkValue)
+
+	_, err = client.CreateTunnelHostRoute(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesCreateParams{Network: "not-an-ip", TunnelID: testTunnelID})
+	assert.ErrorIs(t, err, ErrInvalidNetworkValue)
+
+	assert.Equal(t, []string{"10.0.0.1/32", "2001:db8::1/128"}, created)
+}
+
+func TestCreateTunnelRouteIfAbsent(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes/network/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method, "Expected method 'POST', got %s", r.Method)
+		network := strings.TrimPrefix(r.URL.Path, "/accounts/"+testAccountID+"/teamnet/routes/network/")
+		w.Header().Set("content-type", "application/json")
+		if network == "10.0.0.0/16" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"success": false, "errors": [{"code": 1014, "message": "route already exists"}], "messages": [], "result": null}`)
+			return
+		}
+		fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": {"id": "new", "network": "%s", "tunnel_id": "%s"}}`, network, testTunnelID)
+	})
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/virtual_networks", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.URL.Query().Get("is_default"))
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": [{"id": "default-vnet", "is_default_network": true}]}`)
+	})
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		assert.Equal(t, "10.0.0.0/16", r.URL.Query().Get("network_subset"))
+		vnetID := r.URL.Query().Get("virtual_network_id")
+		assert.NotEmpty(t, vnetID)
+
+		// the same network is routed in the default and another virtual
+		// network, and the API filters by virtual network
+		routes := map[string]string{
+			"default-vnet": `{"id": "in-default", "network": "10.0.0.0/16", "tunnel_id": "other", "virtual_network_id": "default-vnet"}`,
+			"vnet":         `{"id": "in-vnet", "network": "10.0.0.0/16", "tunnel_id": "other", "virtual_network_id": "vnet"}`,
+		}
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": [%s]}`, routes[vnetID])
+	})
+
+	route, created, err := client.CreateTunnelRouteIfAbsent(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesCreateParams{Network: "10.1.0.0/16", TunnelID: testTunnelID})
+	if assert.NoError(t, err) {
+		assert.True(t, created)
+		assert.Equal(t, "new", route.ID)
+	}
+
+	route, created, err = client.CreateTunnelRouteIfAbsent(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesCreateParams{Network: "10.0.0.0/16", TunnelID: testTunnelID, VirtualNetworkID: "vnet"})
+	if assert.NoError(t, err) {
+		assert.False(t, created)
+		assert.Equal(t, "in-vnet", route.ID)
+		assert.Equal(t, "other", route.TunnelID)
+	}
+
+	route, created, err = client.CreateTunnelRouteIfAbsent(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesCreateParams{Network: "10.0.0.0/16", TunnelID: testTunnelID})
+	if assert.NoError(t, err) {
+		assert.False(t, created)
+		assert.Equal(t, "in-default", route.ID)
+	}
+
+	_, created, err = client.CreateTunnelRouteIfAbsent(context.Background(), AccountIdentifier(testAccountID), TunnelRoutesCreateParams{Network: "10.0.0.1", TunnelID: testTunnelID})
+	assert.ErrorIs(t, err, ErrInvalidNetworkCIDR)
+	assert.False(t, created)
+}
+
+func TestGroupTunnelRoutes(t *testing.T) {
+	routes := []TunnelRoute{
+		{Network: "10.0.0.0/16", TunnelID: "b", TunnelName: "office"},
+		{Network: "10.1.0.0/16", TunnelID: "a", TunnelName: "datacenter"},
+		{Network: "10.2.0.0/16", TunnelID: "b", TunnelName: "office"},
+		{Network: "10.3.0.0/16", TunnelID: "c", TunnelName: "office"},
+	}
+
+	assert.Equal(t, map[string][]TunnelRoute{
+		"a": {routes[1]},
+		"b": {routes[0], routes[2]},
+		"c": {routes[3]},
+	}, GroupTunnelRoutesByTunnel(routes))
+
+	assert.Equal(t, map[string][]TunnelRoute{
+		"datacenter": {routes[1]},
+		"office":     {routes[0], routes[2], routes[3]},
+	}, GroupTunnelRoutesByTunnelName(routes))
+
+	assert.Equal(t, []TunnelRouteGroup{
+		{TunnelID: "a", TunnelName: "datacenter", Routes: []TunnelRoute{routes[1]}},
+		{TunnelID: "b", TunnelName: "office", Routes: []TunnelRoute{routes[0], routes[2]}},
+		{TunnelID: "c", TunnelName: "office", Routes: []TunnelRoute{routes[3]}},
+	}, TunnelRouteGroups(routes))
+
+	assert.Empty(t, GroupTunnelRoutesByTunnel(nil))
+	assert.Empty(t, TunnelRouteGroups(nil))
+}
+
+func TestPingTeamnet(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		assert.Equal(t, "1", r.URL.Query().Get("per_page"))
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": []}`)
+	})
+	mux.HandleFunc("/accounts/forbidden/teamnet/routes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"success": false, "errors": [{"code": 10000, "message": "Authentication error"}], "messages": [], "result": null}`)
+	})
+
+	assert.NoError(t, client.PingTeamnet(context.Background(), AccountIdentifier(testAccountID)))
+
+	err := client.PingTeamnet(context.Background(), AccountIdentifier("forbidden"))
+	assert.ErrorIs(t, err, ErrTunnelRoutePermission)
+	assert.True(t, IsAuthFailure(err))
+	assert.Contains(t, err.Error(), "forbidden")
+
+	assert.ErrorIs(t, client.PingTeamnet(context.Background(), AccountIdentifier("")), ErrMissingAccountID)
 }
@@ -2,12 +2,16 @@ package cloudflare
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/goccy/go-json"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -136,6 +140,9 @@ func TestGetTunnel(t *testing.T) {
 	if assert.NoError(t, err) {
 		assert.Equal(t, want, actual)
 	}
+
+	_, err = client.GetTunnel(context.Background(), AccountIdentifier(testAccountID), "")
+	assert.ErrorIs(t, err, ErrMissingTunnelID)
 }
 
 func TestCreateTunnel(t *testing.T) {
@@ -288,6 +295,31 @@ func TestGetTunnelConfiguration(t *testing.T) {
 	}
 }
 
+func TestUpdateTunnelWarpRouting(t *testing.T) {
+	setup()
+	defer teardown()
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.Contains(t, string(body), `"warp-routing":{}`)
+			assert.Contains(t, string(body), `"hostname":"test.example.com"`, "existing ingress rules should be preserved")
+		}
+
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, loadFixture("tunnel", "configuration"))
+	}
+
+	mux.HandleFunc(fmt.Sprintf("/accounts/%s/cfd_tunnel/%s/configurations", testAccountID, testTunnelID), handler)
+
+	_, err := client.UpdateTunnelWarpRouting(context.Background(), AccountIdentifier(testAccountID), UpdateTunnelWarpRoutingParams{TunnelID: testTunnelID, Enabled: false})
+	assert.NoError(t, err)
+
+	_, err = client.UpdateTunnelWarpRouting(context.Background(), AccountIdentifier(testAccountID), UpdateTunnelWarpRoutingParams{Enabled: true})
+	assert.ErrorIs(t, err, ErrMissingTunnelID)
+}
+
 func TestTunnelConnections(t *testing.T) {
 	setup()
 	defer teardown()
@@ -374,6 +406,12 @@ func TestDeleteTunnel(t *testing.T) {
 
 	err := client.DeleteTunnel(context.Background(), AccountIdentifier(testAccountID), testTunnelID)
 	assert.NoError(t, err)
+
+	err = client.DeleteTunnel(context.Background(), AccountIdentifier(""), testTunnelID)
+	assert.ErrorIs(t, err, ErrMissingAccountID)
+
+	err = client.DeleteTunnel(context.Background(), AccountIdentifier(testAccountID), "")
+	assert.ErrorIs(t, err, ErrMissingTunnelID)
 }
 
 func TestCleanupTunnelConnections(t *testing.T) {
@@ -407,4 +445,106 @@ func TestTunnelToken(t *testing.T) {
 	token, err := client.GetTunnelToken(context.Background(), AccountIdentifier(testAccountID), testTunnelID)
 	assert.NoError(t, err)
 	assert.Equal(t, "ZHNraGdhc2RraGFza2hqZGFza2poZGFza2poYXNrZGpoYWtzamRoa2FzZGpoa2FzamRoa2Rhc2po\na2FzamRoa2FqCg==", token)
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(token, "\n", ""))
+	assert.NoError(t, err)
+	assert.Equal(t, "dskhgasdkhaskhjdaskjhdaskjhaskdjhaksjdhkasdjhkasjdhkdasjhkasjdhkaj\n", string(decoded))
+
+	_, err = client.GetTunnelToken(context.Background(), AccountIdentifier(testAccountID), "")
+	assert.ErrorIs(t, err, ErrMissingTunnelID)
+}
+
+func TestRouteTunnelDNS(t *testing.T) {
+	target := testTunnelID + ".cfargotunnel.com"
+	testCases := map[string]struct {
+		existing        string
+		errorOnConflict bool
+		wantMethod      string
+		wantErr         error
+	}{
+		"no existing record": {
+			existing:   ``,
+			wantMethod: http.MethodPost,
+		},
+		"already routed": {
+			existing: `{"id": "372e67954025e0ba6aaa6d586b9e0b59", "type": "CNAME", "name": "app.example.com", "content": "` + target + `", "proxied": true}`,
+		},
+		"existing record is updated": {
+			existing:   `{"id": "372e67954025e0ba6aaa6d586b9e0b59", "type": "A", "name": "app.example.com", "content": "198.51.100.4", "proxied": false, "comment": "keep"}`,
+			wantMethod: http.MethodPatch,
+		},
+		"unproxied record for the tunnel is updated": {
+			existing:        `{"id": "372e67954025e0ba6aaa6d586b9e0b59", "type": "CNAME", "name": "app.example.com", "content": "` + target + `", "proxied": false, "comment": "keep"}`,
+			errorOnConflict: true,
+			wantMethod:      http.MethodPatch,
+		},
+		"existing record conflicts": {
+			existing:        `{"id": "372e67954025e0ba6aaa6d586b9e0b59", "type": "A", "name": "app.example.com", "content": "198.51.100.4"}`,
+			errorOnConflict: true,
+			wantErr:         ErrTunnelDNSConflict,
+		},
+		"multiple existing records": {
+			existing: `{"id": "372e67954025e0ba6aaa6d586b9e0b59", "type": "A", "name": "app.example.com", "content": "198.51.100.4"},
+				{"id": "372e67954025e0ba6aaa6d586b9e0b5a", "type": "AAAA", "name": "app.example.com", "content": "2001:db8::4"}`,
+			wantErr: ErrTunnelDNSConflict,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			setup()
+			defer teardown()
+
+			var gotMethod string
+			mux.HandleFunc("/zones/"+testZoneID+"/dns_records", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("content-type", "application/json")
+				if r.Method == http.MethodGet {
+					assert.Equal(t, "app.example.com", r.URL.Query().Get("name"))
+					fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": [%s], "result_info": {"page": 1, "per_page": 100, "count": 1, "total_count": 1}}`, tc.existing)
+					return
+				}
+
+				gotMethod = r.Method
+				var body CreateDNSRecordParams
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.Equal(t, "CNAME", body.Type)
+				assert.Equal(t, "app.example.com", body.Name)
+				assert.Equal(t, target, body.Content)
+				assert.Equal(t, BoolPtr(true), body.Proxied)
+				fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": {"id": "372e67954025e0ba6aaa6d586b9e0b59", "type": "CNAME", "name": "app.example.com", "content": "%s", "proxied": true}}`, target)
+			})
+			mux.HandleFunc("/zones/"+testZoneID+"/dns_records/372e67954025e0ba6aaa6d586b9e0b59", func(w http.ResponseWriter, r *http.Request) {
+				gotMethod = r.Method
+				body, _ := io.ReadAll(r.Body)
+				assert.JSONEq(t, `{"type": "CNAME", "name": "app.example.com", "content": "`+target+`", "proxied": true, "comment": "keep", "tags": null}`, string(body))
+				w.Header().Set("content-type", "application/json")
+				fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": {"id": "372e67954025e0ba6aaa6d586b9e0b59", "type": "CNAME", "name": "app.example.com", "content": "%s", "proxied": true}}`, target)
+			})
+
+			record, err := client.RouteTunnelDNS(context.Background(), ZoneIdentifier(testZoneID), RouteTunnelDNSParams{
+				Hostname:        "app.example.com",
+				TunnelID:        testTunnelID,
+				ErrorOnConflict: tc.errorOnConflict,
+			})
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				assert.Empty(t, gotMethod)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, "CNAME", record.Type)
+				assert.Equal(t, target, record.Content)
+			}
+			assert.Equal(t, tc.wantMethod, gotMethod)
+		})
+	}
+}
+
+func TestRouteTunnelDNS_MissingHostname(t *testing.T) {
+	setup()
+	defer teardown()
+
+	_, err := client.RouteTunnelDNS(context.Background(), ZoneIdentifier(testZoneID), RouteTunnelDNSParams{TunnelID: testTunnelID})
+	assert.ErrorIs(t, err, ErrMissingHostname)
 }
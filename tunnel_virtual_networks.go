@@ -10,7 +10,11 @@ import (
 	"github.com/goccy/go-json"
 )
 
-var ErrMissingVnetName = errors.New("required missing virtual network name")
+var (
+	ErrMissingVnetName = errors.New("required missing virtual network name")
+	ErrMissingVnetID   = errors.New("required missing virtual network ID")
+	ErrNoDefaultVnet   = errors.New("account has no default virtual network")
+)
 
 // TunnelVirtualNetwork is segregation of Tunnel IP Routes via Virtualized
 // Networks to handle overlapping private IPs in your origins.
@@ -157,3 +161,38 @@ func (api *API) UpdateTunnelVirtualNetwork(ctx context.Context, rc *ResourceCont
 
 	return resp.Result, nil
 }
+
+// GetDefaultTunnelVirtualNetwork returns the default virtual network of the
+// account, which routes created without a VirtualNetworkID are added to. The
+// API has no account level settings for this; the default is the virtual
+// network with IsDefaultNetwork set.
+func (api *API) GetDefaultTunnelVirtualNetwork(ctx context.Context, rc *ResourceContainer) (TunnelVirtualNetwork, error) {
+	vnets, err := api.ListTunnelVirtualNetworks(ctx, rc, TunnelVirtualNetworksListParams{
+		IsDefault: BoolPtr(true),
+		IsDeleted: BoolPtr(false),
+	})
+	if err != nil {
+		return TunnelVirtualNetwork{}, err
+	}
+
+	for _, vnet := range vnets {
+		if vnet.IsDefaultNetwork {
+			return vnet, nil
+		}
+	}
+
+	return TunnelVirtualNetwork{}, ErrNoDefaultVnet
+}
+
+// SetDefaultTunnelVirtualNetwork makes an existing virtual network the default
+// of the account. The previous default stops being the default.
+func (api *API) SetDefaultTunnelVirtualNetwork(ctx context.Context, rc *ResourceContainer, vnetID string) (TunnelVirtualNetwork, error) {
+	if vnetID == "" {
+		return TunnelVirtualNetwork{}, ErrMissingVnetID
+	}
+
+	return api.UpdateTunnelVirtualNetwork(ctx, rc, TunnelVirtualNetworkUpdateParams{
+		VnetID:           vnetID,
+		IsDefaultNetwork: BoolPtr(true),
+	})
+}
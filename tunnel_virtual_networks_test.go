@@ -183,3 +183,52 @@ func TestDeleteTunnelVirtualNetwork(t *testing.T) {
 
 	assert.NoError(t, err)
 }
+
+func TestGetDefaultTunnelVirtualNetwork(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var result string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		assert.Equal(t, "true", r.URL.Query().Get("is_default"))
+		assert.Equal(t, "false", r.URL.Query().Get("is_deleted"))
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": %s}`, result)
+	}
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/virtual_networks", handler)
+
+	result = `[{"id": "f70ff985-a4ef-4643-bbbc-4a0ed4fc8415", "name": "us-east-1-vpc", "is_default_network": true}]`
+	vnet, err := client.GetDefaultTunnelVirtualNetwork(context.Background(), AccountIdentifier(testAccountID))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "f70ff985-a4ef-4643-bbbc-4a0ed4fc8415", vnet.ID)
+	}
+
+	result = `[]`
+	_, err = client.GetDefaultTunnelVirtualNetwork(context.Background(), AccountIdentifier(testAccountID))
+	assert.ErrorIs(t, err, ErrNoDefaultVnet)
+}
+
+func TestSetDefaultTunnelVirtualNetwork(t *testing.T) {
+	setup()
+	defer teardown()
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method, "Expected method 'PATCH', got %s", r.Method)
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"is_default_network": true}`, string(body))
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": {"id": "f70ff985-a4ef-4643-bbbc-4a0ed4fc8415", "name": "us-east-1-vpc", "is_default_network": true}}`)
+	}
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/teamnet/virtual_networks/f70ff985-a4ef-4643-bbbc-4a0ed4fc8415", handler)
+
+	vnet, err := client.SetDefaultTunnelVirtualNetwork(context.Background(), AccountIdentifier(testAccountID), "f70ff985-a4ef-4643-bbbc-4a0ed4fc8415")
+	if assert.NoError(t, err) {
+		assert.True(t, vnet.IsDefaultNetwork)
+	}
+
+	_, err = client.SetDefaultTunnelVirtualNetwork(context.Background(), AccountIdentifier(testAccountID), "")
+	assert.ErrorIs(t, err, ErrMissingVnetID)
+}
@@ -0,0 +1,178 @@
+package cloudflare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/goccy/go-json"
+)
+
+// WarpConnectorTunnelCreateParams holds the parameters for creating a WARP
+// Connector tunnel.
+type WarpConnectorTunnelCreateParams struct {
+	Name string `json:"name"`
+}
+
+// ListWarpConnectorTunnels lists all WARP Connector tunnels. The returned
+// tunnels have a TunnelType of TunnelRouteTypeWarpConnector and their IDs can
+// be used as the TunnelID of routes like any other tunnel.
+//
+// API reference: https://developers.cloudflare.com/api/operations/cloudflare-tunnel-list-warp-connector-tunnels
+func (api *API) ListWarpConnectorTunnels(ctx context.Context, rc *ResourceContainer, params TunnelListParams) ([]Tunnel, *ResultInfo, error) {
+	if rc.Identifier == "" {
+		return []Tunnel{}, &ResultInfo{}, ErrMissingAccountID
+	}
+
+	autoPaginate := true
+	if params.PerPage >= 1 || params.Page >= 1 {
+		autoPaginate = false
+	}
+
+	if params.PerPage < 1 {
+		params.PerPage = listTunnelsDefaultPageSize
+	}
+
+	if params.Page < 1 {
+		params.Page = 1
+	}
+
+	var records []Tunnel
+	var listResponse TunnelsDetailResponse
+
+	for {
+		uri := buildURI(fmt.Sprintf("/accounts/%s/warp_connector", rc.Identifier), params)
+		res, err := api.makeRequestContext(ctx, http.MethodGet, uri, nil)
+		if err != nil {
+			return []Tunnel{}, &ResultInfo{}, err
+		}
+
+		err = json.Unmarshal(res, &listResponse)
+		if err != nil {
+			return []Tunnel{}, &ResultInfo{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+		}
+
+		records = append(records, listResponse.Result...)
+		params.ResultInfo = listResponse.ResultInfo.Next()
+		if params.ResultInfo.Done() || !autoPaginate {
+			break
+		}
+	}
+
+	return records, &listResponse.ResultInfo, nil
+}
+
+// GetWarpConnectorTunnel returns a single WARP Connector tunnel.
+//
+// API reference: https://developers.cloudflare.com/api/operations/cloudflare-tunnel-get-a-warp-connector-tunnel
+func (api *API) GetWarpConnectorTunnel(ctx context.Context, rc *ResourceContainer, tunnelID string) (Tunnel, error) {
+	if rc.Identifier == "" {
+		return Tunnel{}, ErrMissingAccountID
+	}
+
+	if tunnelID == "" {
+		return Tunnel{}, ErrMissingTunnelID
+	}
+
+	uri := fmt.Sprintf("/accounts/%s/warp_connector/%s", rc.Identifier, tunnelID)
+
+	res, err := api.makeRequestContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return Tunnel{}, err
+	}
+
+	var tunnelDetailsResponse TunnelDetailResponse
+	err = json.Unmarshal(res, &tunnelDetailsResponse)
+	if err != nil {
+		return Tunnel{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+	}
+
+	return tunnelDetailsResponse.Result, nil
+}
+
+// CreateWarpConnectorTunnel creates a new WARP Connector tunnel for the
+// account. Unlike cloudflared tunnels no secret is required; the connector is
+// run using the token from GetWarpConnectorTunnelToken.
+//
+// API reference: https://developers.cloudflare.com/api/operations/cloudflare-tunnel-create-a-warp-connector-tunnel
+func (api *API) CreateWarpConnectorTunnel(ctx context.Context, rc *ResourceContainer, params WarpConnectorTunnelCreateParams) (Tunnel, error) {
+	if rc.Identifier == "" {
+		return Tunnel{}, ErrMissingAccountID
+	}
+
+	if params.Name == "" {
+		return Tunnel{}, errors.New("missing tunnel name")
+	}
+
+	uri := fmt.Sprintf("/accounts/%s/warp_connector", rc.Identifier)
+
+	res, err := api.makeRequestContext(ctx, http.MethodPost, uri, params)
+	if err != nil {
+		return Tunnel{}, err
+	}
+
+	var tunnelDetailsResponse TunnelDetailResponse
+	err = json.Unmarshal(res, &tunnelDetailsResponse)
+	if err != nil {
+		return Tunnel{}, fmt.Errorf("%s: %w", errUnmarshalError, err)
+	}
+
+	return tunnelDetailsResponse.Result, nil
+}
+
+// DeleteWarpConnectorTunnel removes a single WARP Connector tunnel.
+//
+// API reference: https://developers.cloudflare.com/api/operations/cloudflare-tunnel-delete-a-warp-connector-tunnel
+func (api *API) DeleteWarpConnectorTunnel(ctx context.Context, rc *ResourceContainer, tunnelID string) error {
+	if rc.Identifier == "" {
+		return ErrMissingAccountID
+	}
+
+	if tunnelID == "" {
+		return ErrMissingTunnelID
+	}
+
+	uri := fmt.Sprintf("/accounts/%s/warp_connector/%s", rc.Identifier, tunnelID)
+
+	res, err := api.makeRequestContext(ctx, http.MethodDelete, uri, nil)
+	if err != nil {
+		return err
+	}
+
+	var tunnelDetailsResponse TunnelDetailResponse
+	err = json.Unmarshal(res, &tunnelDetailsResponse)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errUnmarshalError, err)
+	}
+
+	return nil
+}
+
+// GetWarpConnectorTunnelToken returns the token used to run a WARP Connector.
+//
+// API reference: https://developers.cloudflare.com/api/operations/cloudflare-tunnel-get-a-warp-connector-tunnel-token
+func (api *API) GetWarpConnectorTunnelToken(ctx context.Context, rc *ResourceContainer, tunnelID string) (string, error) {
+	if rc.Identifier == "" {
+		return "", ErrMissingAccountID
+	}
+
+	if tunnelID == "" {
+		return "", ErrMissingTunnelID
+	}
+
+	uri := fmt.Sprintf("/accounts/%s/warp_connector/%s/token", rc.Identifier, tunnelID)
+
+	res, err := api.makeRequestContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var tunnelTokenResponse TunnelTokenResponse
+	err = json.Unmarshal(res, &tunnelTokenResponse)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", errUnmarshalError, err)
+	}
+
+	return tunnelTokenResponse.Result, nil
+}
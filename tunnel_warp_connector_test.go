@@ -0,0 +1,138 @@
+package cloudflare
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const warpConnectorTunnelJSON = `{
+  "id": "%s",
+  "name": "office",
+  "created_at": "2009-11-10T23:00:00Z",
+  "deleted_at": null,
+  "connections": [],
+  "tun_type": "warp_connector",
+  "status": "inactive"
+}`
+
+func TestListWarpConnectorTunnels(t *testing.T) {
+	setup()
+	defer teardown()
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		assert.Equal(t, "office", r.URL.Query().Get("name"))
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{
+			"success": true,
+			"errors": [],
+			"messages": [],
+			"result": [`+warpConnectorTunnelJSON+`],
+			"result_info": {"page": 1, "per_page": 100, "count": 1, "total_count": 1}
+		}`, testTunnelID)
+	}
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/warp_connector", handler)
+
+	createdAt, _ := time.Parse(time.RFC3339, "2009-11-10T23:00:00Z")
+	want := []Tunnel{{
+		ID:          testTunnelID,
+		Name:        "office",
+		CreatedAt:   &createdAt,
+		Connections: []TunnelConnection{},
+		TunnelType:  TunnelRouteTypeWarpConnector,
+		Status:      "inactive",
+	}}
+
+	actual, _, err := client.ListWarpConnectorTunnels(context.Background(), AccountIdentifier(testAccountID), TunnelListParams{Name: "office"})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, want, actual)
+	}
+}
+
+func TestGetWarpConnectorTunnel(t *testing.T) {
+	setup()
+	defer teardown()
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": `+warpConnectorTunnelJSON+`}`, testTunnelID)
+	}
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/warp_connector/"+testTunnelID, handler)
+
+	actual, err := client.GetWarpConnectorTunnel(context.Background(), AccountIdentifier(testAccountID), testTunnelID)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, testTunnelID, actual.ID)
+		assert.Equal(t, TunnelRouteTypeWarpConnector, actual.TunnelType)
+	}
+
+	_, err = client.GetWarpConnectorTunnel(context.Background(), AccountIdentifier(testAccountID), "")
+	assert.ErrorIs(t, err, ErrMissingTunnelID)
+}
+
+func TestCreateWarpConnectorTunnel(t *testing.T) {
+	setup()
+	defer teardown()
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method, "Expected method 'POST', got %s", r.Method)
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"name": "office"}`, string(body))
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": `+warpConnectorTunnelJSON+`}`, testTunnelID)
+	}
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/warp_connector", handler)
+
+	actual, err := client.CreateWarpConnectorTunnel(context.Background(), AccountIdentifier(testAccountID), WarpConnectorTunnelCreateParams{Name: "office"})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, testTunnelID, actual.ID)
+		assert.Equal(t, "office", actual.Name)
+	}
+}
+
+func TestDeleteWarpConnectorTunnel(t *testing.T) {
+	setup()
+	defer teardown()
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method, "Expected method 'DELETE', got %s", r.Method)
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{"success": true, "errors": [], "messages": [], "result": `+warpConnectorTunnelJSON+`}`, testTunnelID)
+	}
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/warp_connector/"+testTunnelID, handler)
+
+	err := client.DeleteWarpConnectorTunnel(context.Background(), AccountIdentifier(testAccountID), testTunnelID)
+	assert.NoError(t, err)
+}
+
+func TestGetWarpConnectorTunnelToken(t *testing.T) {
+	setup()
+	defer teardown()
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method, "Expected method 'GET', got %s", r.Method)
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": "ZXhhbXBsZS10b2tlbg=="}`)
+	}
+
+	mux.HandleFunc("/accounts/"+testAccountID+"/warp_connector/"+testTunnelID+"/token", handler)
+
+	token, err := client.GetWarpConnectorTunnelToken(context.Background(), AccountIdentifier(testAccountID), testTunnelID)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "ZXhhbXBsZS10b2tlbg==", token)
+	}
+}